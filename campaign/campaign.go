@@ -0,0 +1,212 @@
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/segment"
+	"go.uber.org/zap"
+)
+
+// ErrCampaignCompleted 任务已完成，不能重复执行
+var ErrCampaignCompleted = errors.New("campaign already completed")
+
+// ErrCampaignRunning 任务正在其他实例或协程中执行
+var ErrCampaignRunning = errors.New("campaign already running")
+
+// runnableBatch 每次轮询取出的待执行任务数
+const runnableBatch = 10
+
+// Job 活动推送任务，按分群分批解析账号并以通用事件分发，每批之后记录进度
+type Job struct {
+	repo       *repo.CampaignRepository
+	dispatcher *notification.EventDispatcher
+	segments   *segment.Registry
+	batchSize  int
+	interval   time.Duration // 批次之间的间隔，用于限流
+}
+
+// NewJob 初始化活动推送任务
+func NewJob(repo *repo.CampaignRepository, dispatcher *notification.EventDispatcher, segments *segment.Registry) *Job {
+	return &Job{
+		repo:       repo,
+		dispatcher: dispatcher,
+		segments:   segments,
+		batchSize:  200,
+		interval:   time.Second,
+	}
+}
+
+// WithThrottle 设置每批的账号数和批次间隔
+func (j *Job) WithThrottle(batchSize int, interval time.Duration) *Job {
+	if batchSize > 0 {
+		j.batchSize = batchSize
+	}
+	j.interval = interval
+	return j
+}
+
+// Run 执行活动推送，从上次记录的游标继续，直到分群内账号全部分发或ctx取消
+// 执行前先原子地将任务置为执行中并加租约，任务在租约内执行中时返回ErrCampaignRunning；每批记录进度时续期租约。
+// ctx取消时任务标记为暂停，再次调用Run即可继续。进程崩溃后租约到期，任务由轮询重新执行，崩溃前的最后一批可能重复分发；
+// 任务被其他实例接管后本次执行返回ErrCampaignRunning
+func (j *Job) Run(ctx context.Context, campaignID uint64) error {
+	claimed, err := j.repo.ClaimCampaign(ctx, campaignID)
+	if err != nil {
+		return fmt.Errorf("claim campaign failed: %w", err)
+	}
+	c, err := j.repo.GetCampaign(ctx, campaignID)
+	if err != nil {
+		return fmt.Errorf("get campaign failed: %w", err)
+	}
+	if !claimed {
+		if c.Status == repo.CampaignStatusCompleted {
+			return ErrCampaignCompleted
+		}
+		return ErrCampaignRunning
+	}
+	resolver, err := j.segments.Lookup(c.Segment)
+	if err != nil {
+		return j.fail(ctx, c, err)
+	}
+	var payload map[string]any
+	if c.Payload != "" {
+		if err := json.Unmarshal([]byte(c.Payload), &payload); err != nil {
+			return j.fail(ctx, c, fmt.Errorf("unmarshal payload failed: %w", err))
+		}
+	}
+
+	logger.ContextInfo(ctx, "campaign.Job.Run: campaign started",
+		zap.Uint64("campaign_id", c.ID),
+		zap.String("segment", c.Segment),
+		zap.Int64("last_account_id", c.LastAccountID),
+		zap.Int64("dispatched", c.Dispatched))
+
+	for {
+		ids, err := resolver.Resolve(ctx, c.LastAccountID, j.batchSize)
+		if err != nil {
+			return j.stop(ctx, c, err)
+		}
+		if len(ids) > 0 {
+			// 事件在队列中异步处理，不随任务ctx取消
+			eventCtx := context.WithoutCancel(ctx)
+			events := make([]notification.Event, 0, len(ids))
+			for _, id := range ids {
+				events = append(events, notification.NewGenericEvent(eventCtx, id, c.Subtype, payload))
+			}
+			if err := j.dispatcher.DispatchBatch(events); err != nil {
+				return j.stop(ctx, c, err)
+			}
+
+			from := c.LastAccountID
+			c.LastAccountID = ids[len(ids)-1]
+			c.Dispatched += int64(len(ids))
+			// 进度写入失败时下次会从旧游标重推本批，宁可重复也不遗漏
+			updated, err := j.repo.UpdateProgress(context.WithoutCancel(ctx), c.ID, from, c.LastAccountID, c.Dispatched)
+			if err != nil {
+				return j.stop(ctx, c, err)
+			}
+			if !updated {
+				// 租约到期后已被其他实例接管，状态由接管的实例维护
+				logger.ContextWarn(ctx, "campaign.Job.Run: campaign taken over by another runner",
+					zap.Uint64("campaign_id", c.ID),
+					zap.Int64("last_account_id", from))
+				return ErrCampaignRunning
+			}
+			logger.ContextDebug(ctx, "campaign.Job.Run: batch dispatched",
+				zap.Uint64("campaign_id", c.ID),
+				zap.Int("count", len(ids)),
+				zap.Int64("last_account_id", c.LastAccountID),
+				zap.Int64("dispatched", c.Dispatched))
+		}
+		if len(ids) < j.batchSize {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return j.stop(ctx, c, ctx.Err())
+		case <-time.After(j.interval):
+		}
+	}
+
+	if err := j.repo.UpdateStatus(ctx, c.ID, repo.CampaignStatusCompleted, ""); err != nil {
+		return fmt.Errorf("update campaign status failed: %w", err)
+	}
+	logger.ContextInfo(ctx, "campaign.Job.Run: campaign completed",
+		zap.Uint64("campaign_id", c.ID),
+		zap.Int64("dispatched", c.Dispatched))
+	return nil
+}
+
+// Start 周期性执行待执行和已暂停的任务，直到ctx取消；ctx取消时执行中的任务标记为暂停
+func (j *Job) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runPending(ctx)
+			}
+		}
+	}()
+}
+
+// runPending 依次执行待执行和已暂停的任务
+func (j *Job) runPending(ctx context.Context) {
+	ids, err := j.repo.ListRunnableCampaigns(ctx, runnableBatch)
+	if err != nil {
+		logger.ContextError(ctx, "campaign.Job.Start: failed to list runnable campaigns", zap.Error(err))
+		return
+	}
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		err := j.Run(ctx, id)
+		switch {
+		case errors.Is(err, ErrCampaignRunning) || errors.Is(err, ErrCampaignCompleted):
+			logger.ContextDebug(ctx, "campaign.Job.Start: campaign claimed by another runner", zap.Uint64("campaign_id", id))
+		case err != nil && ctx.Err() == nil:
+			logger.ContextError(ctx, "campaign.Job.Start: campaign run failed", zap.Uint64("campaign_id", id), zap.Error(err))
+		}
+	}
+}
+
+// stop ctx取消时暂停任务，其他错误标记为失败，游标保留以便继续
+func (j *Job) stop(ctx context.Context, c *repo.Campaign, err error) error {
+	if ctx.Err() != nil {
+		logger.ContextWarn(ctx, "campaign.Job.Run: campaign paused",
+			zap.Uint64("campaign_id", c.ID),
+			zap.Int64("last_account_id", c.LastAccountID))
+		if updateErr := j.repo.UpdateStatus(context.WithoutCancel(ctx), c.ID, repo.CampaignStatusPaused, ""); updateErr != nil {
+			return errors.Join(err, updateErr)
+		}
+		return err
+	}
+	return j.fail(ctx, c, err)
+}
+
+func (j *Job) fail(ctx context.Context, c *repo.Campaign, err error) error {
+	logger.ContextError(ctx, "campaign.Job.Run: campaign failed",
+		zap.Uint64("campaign_id", c.ID),
+		zap.Int64("last_account_id", c.LastAccountID),
+		zap.Error(err))
+	lastError := err.Error()
+	if len(lastError) > 512 {
+		lastError = lastError[:512]
+	}
+	if updateErr := j.repo.UpdateStatus(context.WithoutCancel(ctx), c.ID, repo.CampaignStatusFailed, lastError); updateErr != nil {
+		return errors.Join(err, updateErr)
+	}
+	return err
+}
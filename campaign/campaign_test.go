@@ -0,0 +1,141 @@
+package campaign_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereal3x/notice/campaign"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/segment"
+)
+
+// genericRecorder 记录收到通用事件的账号
+type genericRecorder struct {
+	mu       sync.Mutex
+	accounts []int64
+}
+
+func (h *genericRecorder) Handle(event notification.Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.accounts = append(h.accounts, event.GetAccountID())
+	return nil
+}
+
+func (h *genericRecorder) SupportEventType() notification.EventType {
+	return notification.EventTypeGeneric
+}
+
+func TestRunClaimsCampaignOnce(t *testing.T) {
+	ctx := context.Background()
+	db, err := repo.NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	campaigns := repo.NewCampaignRepository(db)
+	c := &repo.Campaign{Name: "spring", Segment: "all", Subtype: "activity_reminder"}
+	if err := campaigns.CreateCampaign(ctx, c); err != nil {
+		t.Fatalf("CreateCampaign: %v", err)
+	}
+
+	// 解析器阻塞到第二次执行返回，保证两次执行重叠
+	release := make(chan struct{})
+	segments := segment.NewRegistry()
+	static := segment.NewStaticResolver([]int64{1, 2, 3})
+	segments.Register("all", segment.ResolverFunc(func(ctx context.Context, after int64, limit int) ([]int64, error) {
+		<-release
+		return static.Resolve(ctx, after, limit)
+	}))
+	recorder := &genericRecorder{}
+	dispatcher := notification.NewEventDispatcher(ctx, 10).WithSyncMode()
+	dispatcher.RegisterHandler(recorder)
+	job := campaign.NewJob(campaigns, dispatcher, segments).WithThrottle(10, 0)
+
+	first := make(chan error, 1)
+	go func() { first <- job.Run(ctx, c.ID) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, _ := campaigns.GetCampaign(ctx, c.ID)
+		if got.Status == repo.CampaignStatusRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("campaign was not claimed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := job.Run(ctx, c.ID); !errors.Is(err, campaign.ErrCampaignRunning) {
+		t.Fatalf("second Run error = %v, want ErrCampaignRunning", err)
+	}
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(recorder.accounts) != 3 {
+		t.Fatalf("dispatched to %v, want 3 accounts", recorder.accounts)
+	}
+	if err := job.Run(ctx, c.ID); !errors.Is(err, campaign.ErrCampaignCompleted) {
+		t.Fatalf("Run after completion error = %v, want ErrCampaignCompleted", err)
+	}
+}
+
+func TestRunTakesOverExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	db, err := repo.NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	campaigns := repo.NewCampaignRepository(db)
+	c := &repo.Campaign{Name: "spring", Segment: "all", Subtype: "activity_reminder"}
+	if err := campaigns.CreateCampaign(ctx, c); err != nil {
+		t.Fatalf("CreateCampaign: %v", err)
+	}
+	// 模拟执行实例在分发第一个账号后崩溃
+	if claimed, err := campaigns.ClaimCampaign(ctx, c.ID); !claimed || err != nil {
+		t.Fatalf("ClaimCampaign = %v, %v", claimed, err)
+	}
+	if updated, err := campaigns.UpdateProgress(ctx, c.ID, 0, 1, 1); !updated || err != nil {
+		t.Fatalf("UpdateProgress = %v, %v", updated, err)
+	}
+
+	segments := segment.NewRegistry()
+	segments.Register("all", segment.NewStaticResolver([]int64{1, 2, 3}))
+	recorder := &genericRecorder{}
+	dispatcher := notification.NewEventDispatcher(ctx, 10).WithSyncMode()
+	dispatcher.RegisterHandler(recorder)
+	job := campaign.NewJob(campaigns, dispatcher, segments).WithThrottle(10, 0)
+
+	if err := job.Run(ctx, c.ID); !errors.Is(err, campaign.ErrCampaignRunning) {
+		t.Fatalf("Run within lease error = %v, want ErrCampaignRunning", err)
+	}
+	if ids, _ := campaigns.ListRunnableCampaigns(ctx, 10); len(ids) != 0 {
+		t.Fatalf("runnable campaigns within lease = %v", ids)
+	}
+
+	expired := time.Now().Add(-time.Second)
+	if err := db.Model(&repo.Campaign{}).Where("id = ?", c.ID).Update("claimed_until", expired).Error; err != nil {
+		t.Fatalf("expire lease: %v", err)
+	}
+	if ids, _ := campaigns.ListRunnableCampaigns(ctx, 10); len(ids) != 1 || ids[0] != c.ID {
+		t.Fatalf("runnable campaigns after lease expired = %v", ids)
+	}
+	if err := job.Run(ctx, c.ID); err != nil {
+		t.Fatalf("Run after lease expired: %v", err)
+	}
+	if len(recorder.accounts) != 2 || recorder.accounts[0] != 2 {
+		t.Fatalf("dispatched to %v, want accounts 2 and 3", recorder.accounts)
+	}
+	got, _ := campaigns.GetCampaign(ctx, c.ID)
+	if got.Status != repo.CampaignStatusCompleted || got.Dispatched != 3 {
+		t.Fatalf("campaign = status %d, dispatched %d", got.Status, got.Dispatched)
+	}
+
+	// 原执行实例恢复后不能覆盖接管后的进度
+	if updated, err := campaigns.UpdateProgress(ctx, c.ID, 1, 2, 2); updated || err != nil {
+		t.Fatalf("stale UpdateProgress = %v, %v, want false", updated, err)
+	}
+}
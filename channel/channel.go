@@ -0,0 +1,31 @@
+package channel
+
+import (
+	"context"
+)
+
+// Channel 通知投递渠道
+type Channel string
+
+const (
+	ChannelInbox Channel = "inbox" // 站内信（写入tbl_notification）
+	ChannelSMS   Channel = "sms"   // 短信
+	ChannelEmail Channel = "email" // 邮件
+)
+
+// Message 待投递到外部渠道的消息
+type Message struct {
+	AccountID  int64  // 用户账号ID
+	NotifyType int8   // 通知类型，见constants.NOTIFICATION_TYPE_*
+	Title      string // 标题
+	Content    string // 正文
+}
+
+// Sender 渠道发送器接口
+type Sender interface {
+	// Send 投递消息，失败返回错误以便上层重试
+	Send(ctx context.Context, msg *Message) error
+
+	// Channel 返回发送器对应的渠道
+	Channel() Channel
+}
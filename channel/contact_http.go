@@ -0,0 +1,116 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// HTTPContactConfig 账号联系方式查询接口配置
+type HTTPContactConfig struct {
+	Endpoint string            // 查询接口地址，账号ID以account_id查询参数传入
+	Headers  map[string]string // 额外请求头（如鉴权Token）
+	Timeout  time.Duration     // 请求超时，默认3秒
+}
+
+// HTTPContactResolver 通过账号服务的HTTP接口查询账号绑定的手机号、邮箱和偏好语言
+// 接口返回JSON对象，例如 {"phone": "13800000000", "email": "a@example.com", "locale": "en-US"}；
+// HTTP 404或字段为空视为未绑定。同时实现PhoneResolver、EmailResolver和i18n.LocaleResolver
+type HTTPContactResolver struct {
+	config HTTPContactConfig
+	client *http.Client
+}
+
+// contact 查询接口返回的联系方式
+type contact struct {
+	Phone  string `json:"phone"`
+	Email  string `json:"email"`
+	Locale string `json:"locale"`
+}
+
+// NewHTTPContactResolver 创建HTTP联系方式查询
+func NewHTTPContactResolver(config HTTPContactConfig) *HTTPContactResolver {
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Second
+	}
+	return &HTTPContactResolver{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// GetPhone 实现PhoneResolver
+func (r *HTTPContactResolver) GetPhone(ctx context.Context, accountID int64) (string, error) {
+	c, err := r.lookup(ctx, accountID)
+	if err != nil {
+		return "", err
+	}
+	if c == nil || c.Phone == "" {
+		return "", ErrPhoneNotFound
+	}
+	return c.Phone, nil
+}
+
+// GetEmail 实现EmailResolver
+func (r *HTTPContactResolver) GetEmail(ctx context.Context, accountID int64) (string, error) {
+	c, err := r.lookup(ctx, accountID)
+	if err != nil {
+		return "", err
+	}
+	if c == nil || c.Email == "" {
+		return "", ErrEmailNotFound
+	}
+	return c.Email, nil
+}
+
+// ResolveLocale 实现i18n.LocaleResolver，账号不存在或未设置时返回空字符串
+func (r *HTTPContactResolver) ResolveLocale(ctx context.Context, accountID int64) (string, error) {
+	c, err := r.lookup(ctx, accountID)
+	if err != nil || c == nil {
+		return "", err
+	}
+	return c.Locale, nil
+}
+
+// lookup 查询账号的联系方式，账号不存在时返回nil
+func (r *HTTPContactResolver) lookup(ctx context.Context, accountID int64) (*contact, error) {
+	endpoint, err := url.Parse(r.config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse contact endpoint failed: %w", err)
+	}
+	query := endpoint.Query()
+	query.Set("account_id", strconv.FormatInt(accountID, 10))
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build contact request failed: %w", err)
+	}
+	for k, v := range r.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("contact request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("contact service returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	var c contact
+	if err := json.NewDecoder(resp.Body).Decode(&c); err != nil {
+		return nil, fmt.Errorf("decode contact response failed: %w", err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,58 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPContactResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Query().Get("account_id") {
+		case "1":
+			w.Write([]byte(`{"phone": "13800000000", "email": "a@example.com", "locale": "en-US"}`))
+		case "2":
+			w.Write([]byte(`{}`))
+		case "3":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	contacts := NewHTTPContactResolver(HTTPContactConfig{Endpoint: server.URL, Headers: map[string]string{"Authorization": "token"}})
+	ctx := context.Background()
+
+	if phone, err := contacts.GetPhone(ctx, 1); err != nil || phone != "13800000000" {
+		t.Fatalf("GetPhone(1) = %q, %v", phone, err)
+	}
+	for _, accountID := range []int64{2, 4} {
+		if _, err := contacts.GetPhone(ctx, accountID); !errors.Is(err, ErrPhoneNotFound) {
+			t.Fatalf("GetPhone(%d) error = %v, want ErrPhoneNotFound", accountID, err)
+		}
+	}
+	if _, err := contacts.GetPhone(ctx, 3); err == nil || errors.Is(err, ErrPhoneNotFound) {
+		t.Fatalf("GetPhone(3) error = %v, want a service error", err)
+	}
+
+	if email, err := contacts.GetEmail(ctx, 1); err != nil || email != "a@example.com" {
+		t.Fatalf("GetEmail(1) = %q, %v", email, err)
+	}
+	if _, err := contacts.GetEmail(ctx, 2); !errors.Is(err, ErrEmailNotFound) {
+		t.Fatalf("GetEmail(2) error = %v, want ErrEmailNotFound", err)
+	}
+	if locale, err := contacts.ResolveLocale(ctx, 1); err != nil || locale != "en-US" {
+		t.Fatalf("ResolveLocale(1) = %q, %v", locale, err)
+	}
+	for _, accountID := range []int64{2, 4} {
+		if locale, err := contacts.ResolveLocale(ctx, accountID); err != nil || locale != "" {
+			t.Fatalf("ResolveLocale(%d) = %q, %v, want empty", accountID, locale, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package channel
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// ErrEmailNotFound 账号未绑定邮箱
+var ErrEmailNotFound = errors.New("email not found")
+
+// EmailResolver 根据账号查询邮箱
+type EmailResolver interface {
+	// GetEmail 查询账号绑定的邮箱，未绑定时返回ErrEmailNotFound
+	GetEmail(ctx context.Context, accountID int64) (string, error)
+}
+
+// EmailResolverFunc 函数形式的EmailResolver
+type EmailResolverFunc func(ctx context.Context, accountID int64) (string, error)
+
+func (f EmailResolverFunc) GetEmail(ctx context.Context, accountID int64) (string, error) {
+	return f(ctx, accountID)
+}
+
+// SMTPConfig SMTP邮件配置
+type SMTPConfig struct {
+	Addr     string        // SMTP服务地址，例如"smtp.example.com:587"
+	Username string        // 认证用户名，为空则不认证
+	Password string        // 认证密码
+	From     string        // 发件人地址
+	Timeout  time.Duration // 单封邮件从建立连接到发送完成的超时，默认30秒
+}
+
+// EmailSender 基于SMTP的邮件发送器
+type EmailSender struct {
+	emails EmailResolver
+	config SMTPConfig
+}
+
+func NewEmailSender(emails EmailResolver, config SMTPConfig) *EmailSender {
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &EmailSender{emails: emails, config: config}
+}
+
+func (s *EmailSender) Channel() Channel {
+	return ChannelEmail
+}
+
+func (s *EmailSender) Send(ctx context.Context, msg *Message) error {
+	to, err := s.emails.GetEmail(ctx, msg.AccountID)
+	if err != nil {
+		return fmt.Errorf("get email failed: %w", err)
+	}
+	if to == "" {
+		return ErrEmailNotFound
+	}
+
+	body := strings.Join([]string{
+		"From: " + s.config.From,
+		"To: " + to,
+		"Subject: " + mime.BEncoding.Encode("UTF-8", msg.Title),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		msg.Content,
+	}, "\r\n")
+
+	if err := s.sendMail(ctx, to, []byte(body)); err != nil {
+		return fmt.Errorf("smtp send failed: %w", err)
+	}
+	return nil
+}
+
+// sendMail 与smtp.SendMail流程相同，但连接受ctx和超时控制，SMTP服务无响应时不会一直阻塞worker
+func (s *EmailSender) sendMail(ctx context.Context, to string, body []byte) error {
+	host, _, _ := strings.Cut(s.config.Addr, ":")
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.config.Addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	// ctx取消时关闭连接，中断正在进行的读写
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if s.config.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.config.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,99 @@
+package channel
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer 只实现发送一封邮件所需命令的SMTP服务，hang为true时接受连接后不响应
+func fakeSMTPServer(t *testing.T, hang bool) (addr string, received chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	received = make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if hang {
+			// 保持连接直到客户端关闭
+			conn.Read(make([]byte, 1))
+			return
+		}
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 fake")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func staticEmail(email string) EmailResolver {
+	return EmailResolverFunc(func(ctx context.Context, accountID int64) (string, error) {
+		return email, nil
+	})
+}
+
+func TestEmailSenderSend(t *testing.T) {
+	addr, received := fakeSMTPServer(t, false)
+	s := NewEmailSender(staticEmail("user@example.com"), SMTPConfig{Addr: addr, From: "notice@example.com", Timeout: 5 * time.Second})
+	if err := s.Send(context.Background(), &Message{AccountID: 1, Title: "标题", Content: "hello"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	data := <-received
+	if !strings.Contains(data, "To: user@example.com") || !strings.Contains(data, "hello") {
+		t.Fatalf("received message = %q", data)
+	}
+}
+
+func TestEmailSenderStopsOnHungServer(t *testing.T) {
+	addr, _ := fakeSMTPServer(t, true)
+	s := NewEmailSender(staticEmail("user@example.com"), SMTPConfig{Addr: addr, From: "notice@example.com", Timeout: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := s.Send(ctx, &Message{AccountID: 1, Title: "t", Content: "c"}); err == nil {
+		t.Fatal("Send to a hung server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Send returned after %s, want it to stop when ctx expires", elapsed)
+	}
+}
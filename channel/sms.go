@@ -0,0 +1,163 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereal3x/apc/logger"
+	"go.uber.org/zap"
+)
+
+// ErrPhoneNotFound 账号未绑定手机号
+var ErrPhoneNotFound = errors.New("phone not found")
+
+// 短信分段长度（按字符计）
+const (
+	smsGSM7SingleLen = 160 // 纯GSM-7字符单条长度
+	smsGSM7PartLen   = 153 // 纯GSM-7字符长短信每段长度
+	smsUCS2SingleLen = 70  // 含中文等UCS-2字符单条长度
+	smsUCS2PartLen   = 67  // 含中文等UCS-2字符长短信每段长度
+)
+
+// SMSProvider 短信服务商接口
+type SMSProvider interface {
+	// Name 服务商名称，用于日志
+	Name() string
+
+	// Send 向手机号发送短信内容
+	Send(ctx context.Context, phone string, content string) error
+}
+
+// PhoneResolver 根据账号查询手机号
+type PhoneResolver interface {
+	// GetPhone 查询账号绑定的手机号，未绑定时返回ErrPhoneNotFound
+	GetPhone(ctx context.Context, accountID int64) (string, error)
+}
+
+// PhoneResolverFunc 函数形式的PhoneResolver
+type PhoneResolverFunc func(ctx context.Context, accountID int64) (string, error)
+
+func (f PhoneResolverFunc) GetPhone(ctx context.Context, accountID int64) (string, error) {
+	return f(ctx, accountID)
+}
+
+// SMSConfig 短信渠道配置
+type SMSConfig struct {
+	Signature   string // 短信签名，例如"【征文平台】"，为空则不添加
+	MaxSegments int    // 最多允许的分段数，超出部分截断，<=0表示不限制
+}
+
+// SMSSender 短信发送器，按顺序在多个服务商之间故障转移
+type SMSSender struct {
+	providers []SMSProvider
+	phones    PhoneResolver
+	config    SMSConfig
+}
+
+// NewSMSSender 创建短信发送器，providers按优先级排列
+func NewSMSSender(phones PhoneResolver, config SMSConfig, providers ...SMSProvider) *SMSSender {
+	return &SMSSender{
+		providers: providers,
+		phones:    phones,
+		config:    config,
+	}
+}
+
+func (s *SMSSender) Channel() Channel {
+	return ChannelSMS
+}
+
+// Send 发送短信，依次尝试各服务商，任一成功即返回
+func (s *SMSSender) Send(ctx context.Context, msg *Message) error {
+	if len(s.providers) == 0 {
+		return errors.New("no sms provider configured")
+	}
+
+	phone, err := s.phones.GetPhone(ctx, msg.AccountID)
+	if err != nil {
+		return fmt.Errorf("get phone failed: %w", err)
+	}
+	if phone == "" {
+		return ErrPhoneNotFound
+	}
+
+	content := s.buildContent(msg)
+	segments := SMSSegments(content)
+
+	var errs []error
+	for _, provider := range s.providers {
+		err := provider.Send(ctx, phone, content)
+		if err == nil {
+			logger.ContextDebug(ctx, "SMSSender.Send: sms sent",
+				zap.String("provider", provider.Name()),
+				zap.Int64("account_id", msg.AccountID),
+				zap.Int("segments", segments))
+			return nil
+		}
+		logger.ContextWarn(ctx, "SMSSender.Send: provider failed, trying next",
+			zap.String("provider", provider.Name()),
+			zap.Int64("account_id", msg.AccountID),
+			zap.Error(err))
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+	return fmt.Errorf("all sms providers failed: %w", errors.Join(errs...))
+}
+
+// buildContent 拼接签名并按分段上限截断
+func (s *SMSSender) buildContent(msg *Message) string {
+	content := s.config.Signature + msg.Content
+	if s.config.MaxSegments > 0 {
+		content = TruncateSMS(content, s.config.MaxSegments)
+	}
+	return content
+}
+
+// SMSSegments 计算短信计费分段数
+// 内容全部为GSM-7字符时按160/153计算，含中文等字符时按70/67计算
+func SMSSegments(content string) int {
+	length, single, part := smsLimits(content)
+	if length == 0 {
+		return 0
+	}
+	if length <= single {
+		return 1
+	}
+	return (length + part - 1) / part
+}
+
+// TruncateSMS 将短信截断到不超过maxSegments段，截断时以"..."结尾
+func TruncateSMS(content string, maxSegments int) string {
+	if maxSegments <= 0 {
+		return content
+	}
+	length, single, part := smsLimits(content)
+	limit := single
+	if maxSegments > 1 {
+		limit = part * maxSegments
+	}
+	if length <= limit {
+		return content
+	}
+
+	const ellipsis = "..."
+	runes := []rune(content)
+	return string(runes[:limit-len(ellipsis)]) + ellipsis
+}
+
+// smsLimits 返回内容字符数以及对应编码下的单条/分段长度
+func smsLimits(content string) (length, single, part int) {
+	runes := []rune(content)
+	if isGSM7(content) {
+		return len(runes), smsGSM7SingleLen, smsGSM7PartLen
+	}
+	return len(runes), smsUCS2SingleLen, smsUCS2PartLen
+}
+
+// isGSM7 判断内容是否可用GSM-7编码（近似为可打印ASCII）
+func isGSM7(content string) bool {
+	return !strings.ContainsFunc(content, func(r rune) bool {
+		return r > 0x7E || (r < 0x20 && r != '\n' && r != '\r')
+	})
+}
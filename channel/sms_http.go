@@ -0,0 +1,83 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPSMSConfig 通用HTTP短信服务商配置
+type HTTPSMSConfig struct {
+	Name         string            // 服务商名称
+	Endpoint     string            // 发送接口地址
+	Headers      map[string]string // 额外请求头（如鉴权Token）
+	PhoneField   string            // 请求体中手机号字段名，默认"phone"
+	ContentField string            // 请求体中内容字段名，默认"content"
+	Timeout      time.Duration     // 请求超时，默认5秒
+}
+
+// HTTPSMSProvider 通用HTTP短信服务商
+// 以JSON POST方式调用接口，HTTP 2xx视为发送成功
+type HTTPSMSProvider struct {
+	config HTTPSMSConfig
+	client *http.Client
+}
+
+// NewHTTPSMSProvider 创建HTTP短信服务商
+func NewHTTPSMSProvider(config HTTPSMSConfig) *HTTPSMSProvider {
+	if config.Name == "" {
+		config.Name = "http"
+	}
+	if config.PhoneField == "" {
+		config.PhoneField = "phone"
+	}
+	if config.ContentField == "" {
+		config.ContentField = "content"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &HTTPSMSProvider{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+func (p *HTTPSMSProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *HTTPSMSProvider) Send(ctx context.Context, phone string, content string) error {
+	body, err := json.Marshal(map[string]string{
+		p.config.PhoneField:   phone,
+		p.config.ContentField: content,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal sms request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build sms request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range p.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sms request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sms provider returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSMSSegments(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"empty", "", 0},
+		{"gsm7 single", strings.Repeat("a", 160), 1},
+		{"gsm7 two parts", strings.Repeat("a", 161), 2},
+		{"gsm7 two full parts", strings.Repeat("a", 306), 2},
+		{"gsm7 three parts", strings.Repeat("a", 307), 3},
+		{"ucs2 single", strings.Repeat("中", 70), 1},
+		{"ucs2 two parts", strings.Repeat("中", 71), 2},
+		{"ucs2 two full parts", strings.Repeat("中", 134), 2},
+		{"ucs2 three parts", strings.Repeat("中", 135), 3},
+		// 一个中文字符使整条短信按UCS-2计算
+		{"mixed", strings.Repeat("a", 159) + "中", 3},
+		{"newline stays gsm7", strings.Repeat("a", 159) + "\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SMSSegments(tt.content); got != tt.want {
+				t.Fatalf("SMSSegments = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateSMS(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		maxSegments int
+		wantLen     int // 截断后的字符数
+		truncated   bool
+	}{
+		{"unlimited", strings.Repeat("中", 500), 0, 500, false},
+		{"gsm7 fits single", strings.Repeat("a", 160), 1, 160, false},
+		{"gsm7 over single", strings.Repeat("a", 161), 1, 160, true},
+		{"gsm7 fits two", strings.Repeat("a", 306), 2, 306, false},
+		{"gsm7 over two", strings.Repeat("a", 400), 2, 306, true},
+		{"ucs2 fits single", strings.Repeat("中", 70), 1, 70, false},
+		{"ucs2 over single", strings.Repeat("中", 71), 1, 70, true},
+		{"ucs2 over three", strings.Repeat("中", 300), 3, 201, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateSMS(tt.content, tt.maxSegments)
+			if n := utf8.RuneCountInString(got); n != tt.wantLen {
+				t.Fatalf("TruncateSMS length = %d, want %d", n, tt.wantLen)
+			}
+			if strings.HasSuffix(got, "...") != tt.truncated {
+				t.Fatalf("TruncateSMS = %q, truncated suffix want %v", got, tt.truncated)
+			}
+			if tt.maxSegments > 0 && SMSSegments(got) > tt.maxSegments {
+				t.Fatalf("TruncateSMS result needs %d segments, want at most %d", SMSSegments(got), tt.maxSegments)
+			}
+			if !utf8.ValidString(got) {
+				t.Fatalf("TruncateSMS split a multi-byte character: %q", got)
+			}
+		})
+	}
+}
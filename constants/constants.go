@@ -5,6 +5,14 @@ const (
 	NOTIFICATION_TYPE_MANUSCRIPT_AUDIT    int8 = 1 // 稿件审核通知
 	NOTIFICATION_TYPE_CERTIFICATION_AUDIT int8 = 2 // 认证审核通知
 	NOTIFICATION_TYPE_REWARD_DISTRIBUTE   int8 = 3 // 奖励发放通知
+	NOTIFICATION_TYPE_DIGEST              int8 = 4 // 汇总通知
+)
+
+// 通知分类常量，对应客户端的标签页
+const (
+	NOTIFICATION_CATEGORY_AUDIT  = "audit"  // 审核
+	NOTIFICATION_CATEGORY_REWARD = "reward" // 奖励
+	NOTIFICATION_CATEGORY_SYSTEM = "system" // 系统
 )
 
 // 通知状态常量
@@ -19,3 +27,9 @@ const (
 	MANUSCRIPT_AUDIT_STATUS_APPROVED int8 = 2 // 审核通过
 	MANUSCRIPT_AUDIT_STATUS_REJECTED int8 = 3 // 审核未通过
 )
+
+// 认证审核状态常量
+const (
+	CERTIFICATION_AUDIT_STATUS_APPROVED int8 = 1 // 认证通过
+	CERTIFICATION_AUDIT_STATUS_REJECTED int8 = 2 // 认证未通过
+)
@@ -0,0 +1,217 @@
+package generic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/constants"
+	"github.com/ethereal3x/notice/template"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+var (
+	ErrUnknownSubtype = errors.New("unknown generic notice subtype")
+	ErrInvalidPayload = errors.New("invalid generic notice payload")
+	ErrTypeConflict   = errors.New("generic notice type conflict")
+)
+
+// builtinTypes 由Go代码实现的通知类型，通用通知不能复用
+var builtinTypes = map[int8]bool{
+	constants.NOTIFICATION_TYPE_MANUSCRIPT_AUDIT:    true,
+	constants.NOTIFICATION_TYPE_CERTIFICATION_AUDIT: true,
+	constants.NOTIFICATION_TYPE_REWARD_DISTRIBUTE:   true,
+	constants.NOTIFICATION_TYPE_DIGEST:              true,
+}
+
+// TemplateDefinition 通用通知的模板定义
+type TemplateDefinition struct {
+	Channel channel.Channel `json:"channel"` // 为空表示站内信
+	Locale  string          `json:"locale"`  // 为空表示默认语言
+	Title   string          `json:"title"`
+	Content string          `json:"content"`
+}
+
+// Definition 通用通知类型定义，由产品配置，无需编写Go代码
+type Definition struct {
+	Subtype    string               `json:"subtype"`     // 子类型，事件通过该字段关联定义
+	NotifyType int8                 `json:"notify_type"` // 写入tbl_notification.type的通知类型
+	Category   string               `json:"category"`    // 通知分类，为空时归入系统分类
+	Schema     json.RawMessage      `json:"schema"`      // payload的JSON Schema
+	Templates  []TemplateDefinition `json:"templates"`   // 渲染模板，payload字段通过 {{.Payload.xxx}} 引用
+}
+
+type entry struct {
+	def    *Definition
+	schema *jsonschema.Schema
+}
+
+// Registry 通用通知类型注册表
+// 同时实现template.Loader，可将定义中的模板注册到模板引擎
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*entry)}
+}
+
+// Register 注册或替换通用通知类型定义
+// 通知类型不能与内置类型或其他子类型重复，否则模板和未读数会串到一起
+func (r *Registry) Register(def *Definition) error {
+	if def.Subtype == "" {
+		return errors.New("generic notice subtype is empty")
+	}
+	if def.NotifyType <= 0 {
+		return fmt.Errorf("generic notice %s: invalid notify type %d", def.Subtype, def.NotifyType)
+	}
+	if builtinTypes[def.NotifyType] {
+		return fmt.Errorf("%w: %s uses built-in notify type %d", ErrTypeConflict, def.Subtype, def.NotifyType)
+	}
+
+	schema, err := compileSchema(def.Subtype, def.Schema)
+	if err != nil {
+		return fmt.Errorf("generic notice %s: compile schema failed: %w", def.Subtype, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for subtype, e := range r.entries {
+		if subtype != def.Subtype && e.def.NotifyType == def.NotifyType {
+			return fmt.Errorf("%w: %s and %s both use notify type %d", ErrTypeConflict, def.Subtype, subtype, def.NotifyType)
+		}
+	}
+	r.entries[def.Subtype] = &entry{def: def, schema: schema}
+	return nil
+}
+
+// LoadDir 从目录加载 *.json 格式的定义文件，每个文件一个定义
+func (r *Registry) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read generic notice definition %s failed: %w", file, err)
+		}
+		var def Definition
+		if err := json.Unmarshal(data, &def); err != nil {
+			return fmt.Errorf("parse generic notice definition %s failed: %w", file, err)
+		}
+		if err := r.Register(&def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup 查询子类型定义
+func (r *Registry) Lookup(subtype string) (*Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.entries[subtype]
+	if !ok {
+		return nil, false
+	}
+	return e.def, true
+}
+
+// HasNotifyType 是否有子类型使用该通知类型
+func (r *Registry) HasNotifyType(notifyType int8) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range r.entries {
+		if e.def.NotifyType == notifyType {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 使用子类型注册的JSON Schema校验payload
+func (r *Registry) Validate(subtype string, payload map[string]any) error {
+	r.mu.RLock()
+	e, ok := r.entries[subtype]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownSubtype, subtype)
+	}
+	if e.schema == nil {
+		return nil
+	}
+
+	// 经过一次JSON编解码，使payload中的数字等类型与schema校验器的期望一致
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if err := e.schema.Validate(doc); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return nil
+}
+
+// Load 实现template.Loader，按子类型排序返回全部定义中的模板，保证每次加载的顺序一致
+func (r *Registry) Load(ctx context.Context) ([]*template.Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	subtypes := make([]string, 0, len(r.entries))
+	for subtype := range r.entries {
+		subtypes = append(subtypes, subtype)
+	}
+	sort.Strings(subtypes)
+
+	var templates []*template.Template
+	for _, subtype := range subtypes {
+		e := r.entries[subtype]
+		for _, t := range e.def.Templates {
+			key := template.Key{
+				Type:    e.def.NotifyType,
+				Channel: t.Channel,
+				Locale:  t.Locale,
+			}
+			if key.Channel == "" {
+				key.Channel = channel.ChannelInbox
+			}
+			if key.Locale == "" {
+				key.Locale = template.DefaultLocale
+			}
+			templates = append(templates, &template.Template{
+				Key:     key,
+				Title:   t.Title,
+				Content: t.Content,
+			})
+		}
+	}
+	return templates, nil
+}
+
+func compileSchema(subtype string, raw json.RawMessage) (*jsonschema.Schema, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	url := "generic://" + subtype + ".json"
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, doc); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(url)
+}
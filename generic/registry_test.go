@@ -0,0 +1,45 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRegisterRejectsTypeConflict(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(&Definition{Subtype: "a", NotifyType: 1}); !errors.Is(err, ErrTypeConflict) {
+		t.Fatalf("Register(built-in type) error = %v, want ErrTypeConflict", err)
+	}
+	if err := r.Register(&Definition{Subtype: "a", NotifyType: 10}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := r.Register(&Definition{Subtype: "b", NotifyType: 10}); !errors.Is(err, ErrTypeConflict) {
+		t.Fatalf("Register(duplicate type) error = %v, want ErrTypeConflict", err)
+	}
+	// 同一子类型可以替换原有定义
+	if err := r.Register(&Definition{Subtype: "a", NotifyType: 10, Category: "activity"}); err != nil {
+		t.Fatalf("Register(replace): %v", err)
+	}
+}
+
+func TestLoadSortedBySubtype(t *testing.T) {
+	r := NewRegistry()
+	for i, subtype := range []string{"c", "a", "d", "b"} {
+		def := &Definition{Subtype: subtype, NotifyType: int8(10 + i), Templates: []TemplateDefinition{{Title: subtype}}}
+		if err := r.Register(def); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+	}
+	templates, err := r.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var got string
+	for _, tpl := range templates {
+		got += tpl.Title
+	}
+	if got != "abcd" {
+		t.Fatalf("Load order = %q, want abcd", got)
+	}
+}
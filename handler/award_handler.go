@@ -6,18 +6,37 @@ import (
 	"time"
 
 	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/channel"
 	"github.com/ethereal3x/notice/constants"
+	"github.com/ethereal3x/notice/i18n"
 	"github.com/ethereal3x/notice/notification"
 	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/template"
 	"go.uber.org/zap"
 )
 
 type AwardHandler struct {
-	repo *repo.NoticeRepository
+	repo         repo.NotificationStore
+	templates    *template.Engine
+	locales      *i18n.Localizer
+	smsEnabled   bool
+	emailEnabled bool
 }
 
-func NewAwardHandler(repo *repo.NoticeRepository) *AwardHandler {
-	return &AwardHandler{repo: repo}
+func NewAwardHandler(repo repo.NotificationStore, templates *template.Engine, locales *i18n.Localizer) *AwardHandler {
+	return &AwardHandler{repo: repo, templates: templates, locales: locales}
+}
+
+// WithSMS 开启现金奖励的短信通知
+func (a *AwardHandler) WithSMS() *AwardHandler {
+	a.smsEnabled = true
+	return a
+}
+
+// WithEmail 开启邮件通知
+func (a *AwardHandler) WithEmail() *AwardHandler {
+	a.emailEnabled = true
+	return a
 }
 
 func (a *AwardHandler) SupportEventType() notification.EventType {
@@ -31,34 +50,9 @@ func (a *AwardHandler) Handle(event notification.Event) error {
 	}
 	ctx := awardEvent.GetContext()
 
-	title := a.buildTitle(awardEvent)
-	content := a.buildContent(awardEvent)
-	ext := map[string]interface{}{
-		"manuscript_id": awardEvent.ManuscriptId,
-		"award_type":    awardEvent.AwardType,
-		"award_amount":  awardEvent.AwardAmount,
-		"activity_name": awardEvent.ActivityName,
-	}
-	extDataJSON, err := json.Marshal(ext)
+	n, data, err := a.buildNotice(awardEvent)
 	if err != nil {
-		logger.ContextError(ctx, "AwardHandler: failed to marshal ext data",
-			zap.String("manuscript_id", awardEvent.ManuscriptId),
-			zap.Int64("account_id", awardEvent.GetAccountID()),
-			zap.String("award_type", awardEvent.AwardType),
-			zap.Int("award_amount", awardEvent.AwardAmount),
-			zap.Error(err))
-		return fmt.Errorf("marshal ext data failed: %w", err)
-	}
-
-	n := &repo.Notification{
-		AccountID: event.GetAccountID(),
-		Type:      constants.NOTIFICATION_TYPE_REWARD_DISTRIBUTE,
-		Title:     title,
-		Content:   content,
-		Status:    constants.NOTIFICATION_STATUS_UNREAD,
-		ExtData:   string(extDataJSON),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		return err
 	}
 
 	// 记录即将插入的通知信息
@@ -93,20 +87,137 @@ func (a *AwardHandler) Handle(event notification.Event) error {
 		zap.Int64("account_id", n.AccountID),
 		zap.Uint64("notification_id", n.ID))
 
+	a.deliver(awardEvent, n, data)
 	return nil
 }
 
-func (a *AwardHandler) buildTitle(event *notification.AwardEvent) string {
-	return "奖励发放通知"
+// HandleBatch 批量处理奖励事件，所有站内信通过一条INSERT落库
+// 渲染失败的事件重试也无法成功，记录日志后跳过，不影响同批的其他事件
+func (a *AwardHandler) HandleBatch(events []notification.Event) error {
+	var (
+		awardEvents []*notification.AwardEvent
+		notices     []*repo.Notification
+		datas       []map[string]interface{}
+	)
+	for _, event := range events {
+		awardEvent, ok := event.(*notification.AwardEvent)
+		if !ok {
+			continue
+		}
+		n, data, err := a.buildNotice(awardEvent)
+		if err != nil {
+			logger.ContextError(awardEvent.GetContext(), "AwardHandler: skip event that cannot be rendered - MESSAGE WILL BE LOST",
+				zap.String("event_type", "award"),
+				zap.String("manuscript_id", awardEvent.ManuscriptId),
+				zap.Int64("account_id", awardEvent.GetAccountID()),
+				zap.String("award_type", awardEvent.AwardType),
+				zap.Int("award_amount", awardEvent.AwardAmount),
+				zap.String("activity_name", awardEvent.ActivityName),
+				zap.Error(err))
+			continue
+		}
+		awardEvents = append(awardEvents, awardEvent)
+		notices = append(notices, n)
+		datas = append(datas, data)
+	}
+	if len(notices) == 0 {
+		return nil
+	}
+
+	ctx := awardEvents[0].GetContext()
+	logger.ContextDebug(ctx, "AwardHandler: inserting notifications",
+		zap.Int("count", len(notices)))
+
+	if _, err := a.repo.InsertNotices(ctx, notices); err != nil {
+		logger.ContextError(ctx, "AwardHandler: DATABASE BATCH INSERT FAILED - MESSAGES WILL BE LOST",
+			zap.String("event_type", "award"),
+			zap.Int("count", len(notices)),
+			zap.Error(err),
+			zap.String("error_type", fmt.Sprintf("%T", err)),
+			zap.Stack("stack_trace"))
+		return fmt.Errorf("insert notifications failed: %w", err)
+	}
+
+	logger.ContextDebug(ctx, "AwardHandler: notifications inserted successfully",
+		zap.Int("count", len(notices)))
+
+	for i, awardEvent := range awardEvents {
+		a.deliver(awardEvent, notices[i], datas[i])
+	}
+	return nil
 }
 
-func (a *AwardHandler) buildContent(event *notification.AwardEvent) string {
-	content := fmt.Sprintf("恭喜您！您在活动【%s】的稿件获得奖励", event.ActivityName)
-	if event.AwardAmount > 0 {
-		content += fmt.Sprintf("，金额：%d", event.AwardAmount)
+// buildNotice 渲染站内信，返回待插入的通知和外部渠道的模板数据
+func (a *AwardHandler) buildNotice(awardEvent *notification.AwardEvent) (*repo.Notification, map[string]interface{}, error) {
+	ctx := awardEvent.GetContext()
+
+	ext := map[string]interface{}{
+		"manuscript_id": awardEvent.ManuscriptId,
+		"award_type":    awardEvent.AwardType,
+		"award_amount":  awardEvent.AwardAmount,
+		"activity_name": awardEvent.ActivityName,
+	}
+	extDataJSON, err := json.Marshal(ext)
+	if err != nil {
+		logger.ContextError(ctx, "AwardHandler: failed to marshal ext data",
+			zap.String("manuscript_id", awardEvent.ManuscriptId),
+			zap.Int64("account_id", awardEvent.GetAccountID()),
+			zap.String("award_type", awardEvent.AwardType),
+			zap.Int("award_amount", awardEvent.AwardAmount),
+			zap.Error(err))
+		return nil, nil, fmt.Errorf("marshal ext data failed: %w", err)
+	}
+
+	locale := a.locales.Locale(ctx, awardEvent.GetAccountID())
+	data := a.templateData(awardEvent, ext)
+	title, content, err := a.templates.Render(a.templateKey(channel.ChannelInbox, locale), data)
+	if err != nil {
+		logger.ContextError(ctx, "AwardHandler: failed to render template",
+			zap.String("manuscript_id", awardEvent.ManuscriptId),
+			zap.Int64("account_id", awardEvent.GetAccountID()),
+			zap.Error(err))
+		return nil, nil, fmt.Errorf("render template failed: %w", err)
 	}
-	if event.AwardType != "" {
-		content += fmt.Sprintf("，类型：%s", event.AwardType)
+
+	n := &repo.Notification{
+		AccountID:    awardEvent.GetAccountID(),
+		Type:         constants.NOTIFICATION_TYPE_REWARD_DISTRIBUTE,
+		Category:     constants.NOTIFICATION_CATEGORY_REWARD,
+		Title:        title,
+		Content:      content,
+		Status:       constants.NOTIFICATION_STATUS_UNREAD,
+		Locale:       locale,
+		ActivityName: awardEvent.ActivityName,
+		ExtData:      string(extDataJSON),
+		ExpiresAt:    notification.ExpiresAt(awardEvent),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	return n, data, nil
+}
+
+// deliver 站内信已落库，外部渠道走独立事件重试
+func (a *AwardHandler) deliver(awardEvent *notification.AwardEvent, n *repo.Notification, data map[string]interface{}) {
+	ctx := awardEvent.GetContext()
+	if a.smsEnabled && awardEvent.AwardAmount > 0 {
+		dispatchDelivery(ctx, a.templates, a.templateKey(channel.ChannelSMS, n.Locale), data, n.AccountID, n.ExpiresAt)
+	}
+	if a.emailEnabled {
+		dispatchDelivery(ctx, a.templates, a.templateKey(channel.ChannelEmail, n.Locale), data, n.AccountID, n.ExpiresAt)
+	}
+}
+
+func (a *AwardHandler) templateKey(ch channel.Channel, locale string) template.Key {
+	return template.Key{
+		Type:    constants.NOTIFICATION_TYPE_REWARD_DISTRIBUTE,
+		Channel: ch,
+		Locale:  locale,
+	}
+}
+
+func (a *AwardHandler) templateData(event *notification.AwardEvent, ext map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"Event": event,
+		"Ext":   ext,
 	}
-	return content
 }
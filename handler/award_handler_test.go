@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/constants"
+	"github.com/ethereal3x/notice/handler"
+	"github.com/ethereal3x/notice/i18n"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/template"
+)
+
+// staticLoader 返回固定的模板
+type staticLoader []*template.Template
+
+func (l staticLoader) Load(ctx context.Context) ([]*template.Template, error) {
+	return l, nil
+}
+
+// newAwardHandler 创建使用内存存储的奖励handler，奖励金额为负数时渲染失败
+func newAwardHandler(t *testing.T, store repo.NotificationStore) *handler.AwardHandler {
+	t.Helper()
+	templates := template.NewEngine(staticLoader{{
+		Key:     template.Key{Type: constants.NOTIFICATION_TYPE_REWARD_DISTRIBUTE, Channel: channel.ChannelInbox, Locale: i18n.DefaultLocale},
+		Title:   `{{if lt .Event.AwardAmount 0}}{{fail}}{{end}}award`,
+		Content: `{{.Event.AwardAmount}}`,
+	}}).Funcs(texttemplate.FuncMap{
+		"fail": func() (string, error) { return "", errors.New("bad amount") },
+	})
+	if err := templates.Reload(context.Background()); err != nil {
+		t.Fatalf("reload templates: %v", err)
+	}
+	bundle, err := i18n.NewDefaultBundle()
+	if err != nil {
+		t.Fatalf("load bundle: %v", err)
+	}
+	return handler.NewAwardHandler(store, templates, i18n.NewLocalizer(i18n.StaticLocaleResolver(i18n.DefaultLocale), bundle))
+}
+
+// deliveryRecorder 记录收到的投递事件
+type deliveryRecorder struct {
+	events []*notification.DeliveryEvent
+}
+
+func (h *deliveryRecorder) Handle(event notification.Event) error {
+	h.events = append(h.events, event.(*notification.DeliveryEvent))
+	return nil
+}
+
+func (h *deliveryRecorder) SupportEventType() notification.EventType {
+	return notification.EventTypeDelivery
+}
+
+func TestAwardHandleBatchSkipsUnrenderableEvent(t *testing.T) {
+	ctx := context.Background()
+	store := repo.NewMemoryStore()
+	h := newAwardHandler(t, store)
+	events := []notification.Event{
+		notification.NewAwardEvent(ctx, 1, "MS001", 100, "cash"),
+		notification.NewAwardEvent(ctx, 1, "MS002", -1, "cash"),
+		notification.NewAwardEvent(ctx, 1, "MS003", 200, "cash"),
+	}
+	if err := h.HandleBatch(events); err != nil {
+		t.Fatalf("HandleBatch: %v", err)
+	}
+	count, _ := store.GetUnreadCount(ctx, 1)
+	if count != 2 {
+		t.Fatalf("unread = %d, want 2", count)
+	}
+}
+
+func TestAwardDeliveryInheritsExpiry(t *testing.T) {
+	ctx := context.Background()
+	recorder := &deliveryRecorder{}
+	dispatcher := notification.NewEventDispatcher(ctx, 10).WithSyncMode()
+	dispatcher.RegisterHandler(recorder)
+	notification.InitGlobalManager(ctx, dispatcher)
+
+	store := repo.NewMemoryStore()
+	h := newAwardHandler(t, store).WithSMS()
+	event := notification.NewAwardEvent(ctx, 1, "MS001", 100, "cash")
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	event.ExpiresAt = &expiresAt
+	if err := h.Handle(event); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if len(recorder.events) != 1 {
+		t.Fatalf("delivered %d events, want 1", len(recorder.events))
+	}
+	if got := recorder.events[0].GetExpiresAt(); got == nil || !got.Equal(expiresAt) {
+		t.Fatalf("delivery expires at %v, want %v", got, expiresAt)
+	}
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/constants"
+	"github.com/ethereal3x/notice/i18n"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/template"
+	"go.uber.org/zap"
+)
+
+type CertificationHandler struct {
+	repo         repo.NotificationStore
+	templates    *template.Engine
+	locales      *i18n.Localizer
+	emailEnabled bool
+}
+
+func NewCertificationHandler(repo repo.NotificationStore, templates *template.Engine, locales *i18n.Localizer) *CertificationHandler {
+	return &CertificationHandler{repo: repo, templates: templates, locales: locales}
+}
+
+// WithEmail 开启邮件通知
+func (c *CertificationHandler) WithEmail() *CertificationHandler {
+	c.emailEnabled = true
+	return c
+}
+
+func (c *CertificationHandler) SupportEventType() notification.EventType {
+	return notification.EventTypeCertification
+}
+
+func (c *CertificationHandler) Handle(event notification.Event) error {
+	certEvent, ok := event.(*notification.CertificationEvent)
+	if !ok {
+		return nil
+	}
+	ctx := certEvent.GetContext()
+
+	ext := map[string]interface{}{
+		"certification_id":   certEvent.CertificationId,
+		"certification_type": certEvent.CertificationType,
+		"status":             certEvent.Status,
+		"audit_reason":       certEvent.AuditReason,
+		"operate_user":       certEvent.OperateUser,
+		"activity_name":      certEvent.ActivityName,
+	}
+	extDataJSON, err := json.Marshal(ext)
+	if err != nil {
+		logger.ContextError(ctx, "CertificationHandler: failed to marshal ext data",
+			zap.String("certification_id", certEvent.CertificationId),
+			zap.Int64("account_id", certEvent.GetAccountID()),
+			zap.Int8("status", certEvent.Status),
+			zap.Error(err))
+		return fmt.Errorf("marshal ext data failed: %w", err)
+	}
+
+	locale := c.locales.Locale(ctx, certEvent.GetAccountID())
+	title, content, err := c.templates.Render(c.templateKey(channel.ChannelInbox, locale), c.templateData(certEvent, ext))
+	if err != nil {
+		logger.ContextError(ctx, "CertificationHandler: failed to render template",
+			zap.String("certification_id", certEvent.CertificationId),
+			zap.Int64("account_id", certEvent.GetAccountID()),
+			zap.Int8("status", certEvent.Status),
+			zap.Error(err))
+		return fmt.Errorf("render template failed: %w", err)
+	}
+
+	n := &repo.Notification{
+		AccountID:    event.GetAccountID(),
+		Type:         constants.NOTIFICATION_TYPE_CERTIFICATION_AUDIT,
+		Category:     constants.NOTIFICATION_CATEGORY_AUDIT,
+		Title:        title,
+		Content:      content,
+		Status:       constants.NOTIFICATION_STATUS_UNREAD,
+		Locale:       locale,
+		ExtData:      string(extDataJSON),
+		ActivityName: certEvent.ActivityName,
+		ExpiresAt:    notification.ExpiresAt(event),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	logger.ContextDebug(ctx, "CertificationHandler: inserting notification",
+		zap.String("certification_id", certEvent.CertificationId),
+		zap.Int64("account_id", n.AccountID),
+		zap.Int8("type", n.Type),
+		zap.String("title", n.Title),
+		zap.String("content", n.Content))
+
+	err = c.repo.InsertNotice(ctx, n)
+	if err != nil {
+		logger.ContextError(ctx, "CertificationHandler: DATABASE INSERT FAILED - MESSAGE WILL BE LOST",
+			zap.String("event_type", "certification_audit"),
+			zap.String("certification_id", certEvent.CertificationId),
+			zap.Int64("account_id", n.AccountID),
+			zap.String("certification_type", certEvent.CertificationType),
+			zap.Int8("status", certEvent.Status),
+			zap.String("audit_reason", certEvent.AuditReason),
+			zap.String("operate_user", certEvent.OperateUser),
+			zap.String("title", n.Title),
+			zap.String("content", n.Content),
+			zap.String("ext_data", n.ExtData),
+			zap.Error(err),
+			zap.String("error_type", fmt.Sprintf("%T", err)),
+			zap.Stack("stack_trace"))
+		return fmt.Errorf("insert notification failed: %w", err)
+	}
+	logger.ContextDebug(ctx, "CertificationHandler: notification inserted successfully",
+		zap.String("certification_id", certEvent.CertificationId),
+		zap.Int64("account_id", n.AccountID),
+		zap.Uint64("notification_id", n.ID))
+
+	if c.emailEnabled {
+		dispatchDelivery(ctx, c.templates, c.templateKey(channel.ChannelEmail, locale), c.templateData(certEvent, ext), n.AccountID, n.ExpiresAt)
+	}
+
+	return nil
+}
+
+func (c *CertificationHandler) templateKey(ch channel.Channel, locale string) template.Key {
+	return template.Key{
+		Type:    constants.NOTIFICATION_TYPE_CERTIFICATION_AUDIT,
+		Channel: ch,
+		Locale:  locale,
+	}
+}
+
+func (c *CertificationHandler) templateData(event *notification.CertificationEvent, ext map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"Event":  event,
+		"Ext":    ext,
+		"Result": c.getResult(event.Status),
+	}
+}
+
+// getResult 返回审核结果在消息目录中的后缀
+func (c *CertificationHandler) getResult(status int8) string {
+	if status == constants.CERTIFICATION_AUDIT_STATUS_APPROVED {
+		return "approved"
+	}
+	return "rejected"
+}
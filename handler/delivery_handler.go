@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/template"
+	"go.uber.org/zap"
+)
+
+// DeliveryHandler 将投递事件路由到对应渠道的发送器
+type DeliveryHandler struct {
+	senders map[channel.Channel]channel.Sender
+}
+
+func NewDeliveryHandler(senders ...channel.Sender) *DeliveryHandler {
+	h := &DeliveryHandler{senders: make(map[channel.Channel]channel.Sender)}
+	for _, s := range senders {
+		h.senders[s.Channel()] = s
+	}
+	return h
+}
+
+func (d *DeliveryHandler) SupportEventType() notification.EventType {
+	return notification.EventTypeDelivery
+}
+
+func (d *DeliveryHandler) Handle(event notification.Event) error {
+	deliveryEvent, ok := event.(*notification.DeliveryEvent)
+	if !ok {
+		return nil
+	}
+	ctx := deliveryEvent.GetContext()
+
+	sender, exist := d.senders[deliveryEvent.Channel]
+	if !exist {
+		// 渠道未配置，重试无意义，直接丢弃
+		logger.ContextWarn(ctx, "DeliveryHandler: no sender for channel, drop message",
+			zap.String("channel", string(deliveryEvent.Channel)),
+			zap.Int64("account_id", deliveryEvent.GetAccountID()),
+			zap.Int8("notify_type", deliveryEvent.NotifyType))
+		return nil
+	}
+
+	msg := &channel.Message{
+		AccountID:  deliveryEvent.GetAccountID(),
+		NotifyType: deliveryEvent.NotifyType,
+		Title:      deliveryEvent.Title,
+		Content:    deliveryEvent.Content,
+	}
+	err := sender.Send(ctx, msg)
+	if errors.Is(err, channel.ErrPhoneNotFound) || errors.Is(err, channel.ErrEmailNotFound) {
+		logger.ContextWarn(ctx, "DeliveryHandler: account has no contact address, drop message",
+			zap.String("channel", string(deliveryEvent.Channel)),
+			zap.Int64("account_id", msg.AccountID))
+		return nil
+	}
+	if err != nil {
+		logger.ContextError(ctx, "DeliveryHandler: send failed",
+			zap.String("channel", string(deliveryEvent.Channel)),
+			zap.Int64("account_id", msg.AccountID),
+			zap.Int8("notify_type", msg.NotifyType),
+			zap.Error(err))
+		return fmt.Errorf("send %s message failed: %w", deliveryEvent.Channel, err)
+	}
+
+	logger.ContextDebug(ctx, "DeliveryHandler: message delivered",
+		zap.String("channel", string(deliveryEvent.Channel)),
+		zap.Int64("account_id", msg.AccountID))
+	return nil
+}
+
+// dispatchDelivery 渲染外部渠道模板并分发投递事件，站内信已落库，渲染或分发失败只记录日志
+// 投递事件沿用站内信的过期时间，过期后仍未发出的投递会被丢弃
+func dispatchDelivery(ctx context.Context, templates *template.Engine, key template.Key, data map[string]interface{}, accountID int64, expiresAt *time.Time) {
+	title, content, err := templates.Render(key, data)
+	if err != nil {
+		logger.ContextError(ctx, "dispatchDelivery: failed to render template, skip delivery",
+			zap.String("channel", string(key.Channel)),
+			zap.Int8("notify_type", key.Type),
+			zap.Int64("account_id", accountID),
+			zap.Error(err))
+		return
+	}
+	if err := notification.DispatchDeliveryEvent(ctx, accountID, key.Channel, key.Type, title, content, expiresAt); err != nil {
+		logger.ContextError(ctx, "dispatchDelivery: failed to dispatch delivery event",
+			zap.String("channel", string(key.Channel)),
+			zap.Int8("notify_type", key.Type),
+			zap.Int64("account_id", accountID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/constants"
+	"github.com/ethereal3x/notice/i18n"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/template"
+	"go.uber.org/zap"
+)
+
+// DigestHandler 处理聚合后的汇总事件，写入一条汇总通知
+type DigestHandler struct {
+	repo         repo.NotificationStore
+	templates    *template.Engine
+	locales      *i18n.Localizer
+	emailEnabled bool
+}
+
+func NewDigestHandler(repo repo.NotificationStore, templates *template.Engine, locales *i18n.Localizer) *DigestHandler {
+	return &DigestHandler{repo: repo, templates: templates, locales: locales}
+}
+
+// WithEmail 开启邮件通知
+func (d *DigestHandler) WithEmail() *DigestHandler {
+	d.emailEnabled = true
+	return d
+}
+
+func (d *DigestHandler) SupportEventType() notification.EventType {
+	return notification.EventTypeDigest
+}
+
+func (d *DigestHandler) Handle(event notification.Event) error {
+	digestEvent, ok := event.(*notification.DigestEvent)
+	if !ok {
+		return nil
+	}
+	ctx := digestEvent.GetContext()
+
+	manuscriptIDs := make([]string, 0, len(digestEvent.Manuscripts)+len(digestEvent.Awards))
+	awardTotal := 0
+	for _, e := range digestEvent.Manuscripts {
+		manuscriptIDs = append(manuscriptIDs, e.ManuscriptId)
+	}
+	for _, e := range digestEvent.Awards {
+		manuscriptIDs = append(manuscriptIDs, e.ManuscriptId)
+		awardTotal += e.AwardAmount
+	}
+	ext := map[string]interface{}{
+		"activity_name":    digestEvent.ActivityName,
+		"manuscript_ids":   manuscriptIDs,
+		"manuscript_count": len(digestEvent.Manuscripts),
+		"award_count":      len(digestEvent.Awards),
+		"award_total":      awardTotal,
+	}
+	extDataJSON, err := json.Marshal(ext)
+	if err != nil {
+		logger.ContextError(ctx, "DigestHandler: failed to marshal ext data",
+			zap.Int64("account_id", digestEvent.GetAccountID()),
+			zap.String("activity_name", digestEvent.ActivityName),
+			zap.Error(err))
+		return fmt.Errorf("marshal ext data failed: %w", err)
+	}
+
+	locale := d.locales.Locale(ctx, digestEvent.GetAccountID())
+	data := map[string]interface{}{
+		"Event": digestEvent,
+		"Ext":   ext,
+		"Total": len(digestEvent.Manuscripts) + len(digestEvent.Awards),
+	}
+	title, content, err := d.templates.Render(d.templateKey(channel.ChannelInbox, locale), data)
+	if err != nil {
+		logger.ContextError(ctx, "DigestHandler: failed to render template",
+			zap.Int64("account_id", digestEvent.GetAccountID()),
+			zap.String("activity_name", digestEvent.ActivityName),
+			zap.Error(err))
+		return fmt.Errorf("render template failed: %w", err)
+	}
+
+	n := &repo.Notification{
+		AccountID:    event.GetAccountID(),
+		Type:         constants.NOTIFICATION_TYPE_DIGEST,
+		Category:     constants.NOTIFICATION_CATEGORY_SYSTEM,
+		Title:        title,
+		Content:      content,
+		Status:       constants.NOTIFICATION_STATUS_UNREAD,
+		Locale:       locale,
+		ActivityName: digestEvent.ActivityName,
+		ExtData:      string(extDataJSON),
+		ExpiresAt:    notification.ExpiresAt(event),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	err = d.repo.InsertNotice(ctx, n)
+	if err != nil {
+		logger.ContextError(ctx, "DigestHandler: DATABASE INSERT FAILED - MESSAGE WILL BE LOST",
+			zap.String("event_type", "digest"),
+			zap.Int64("account_id", n.AccountID),
+			zap.String("activity_name", digestEvent.ActivityName),
+			zap.String("title", n.Title),
+			zap.String("content", n.Content),
+			zap.String("ext_data", n.ExtData),
+			zap.Error(err),
+			zap.String("error_type", fmt.Sprintf("%T", err)),
+			zap.Stack("stack_trace"))
+		return fmt.Errorf("insert notification failed: %w", err)
+	}
+	logger.ContextDebug(ctx, "DigestHandler: notification inserted successfully",
+		zap.Int64("account_id", n.AccountID),
+		zap.String("activity_name", digestEvent.ActivityName),
+		zap.Uint64("notification_id", n.ID))
+
+	if d.emailEnabled {
+		dispatchDelivery(ctx, d.templates, d.templateKey(channel.ChannelEmail, locale), data, n.AccountID, n.ExpiresAt)
+	}
+
+	return nil
+}
+
+func (d *DigestHandler) templateKey(ch channel.Channel, locale string) template.Key {
+	return template.Key{
+		Type:    constants.NOTIFICATION_TYPE_DIGEST,
+		Channel: ch,
+		Locale:  locale,
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/constants"
+	"github.com/ethereal3x/notice/generic"
+	"github.com/ethereal3x/notice/i18n"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/template"
+	"go.uber.org/zap"
+)
+
+// GenericHandler 处理通用事件，按注册表中的定义校验payload并渲染模板写入通知
+type GenericHandler struct {
+	repo      repo.NotificationStore
+	registry  *generic.Registry
+	templates *template.Engine
+	locales   *i18n.Localizer
+}
+
+func NewGenericHandler(repo repo.NotificationStore, registry *generic.Registry, templates *template.Engine, locales *i18n.Localizer) *GenericHandler {
+	return &GenericHandler{repo: repo, registry: registry, templates: templates, locales: locales}
+}
+
+func (g *GenericHandler) SupportEventType() notification.EventType {
+	return notification.EventTypeGeneric
+}
+
+func (g *GenericHandler) Handle(event notification.Event) error {
+	genericEvent, ok := event.(*notification.GenericEvent)
+	if !ok {
+		return nil
+	}
+	ctx := genericEvent.GetContext()
+
+	def, ok := g.registry.Lookup(genericEvent.Subtype)
+	if !ok {
+		// 分发器入队前已校验子类型，这里兜底处理注册表变更等情况，返回ErrInvalidEvent使事件直接进入死信
+		logger.ContextError(ctx, "GenericHandler: unknown subtype, dead-letter event",
+			zap.String("subtype", genericEvent.Subtype),
+			zap.Int64("account_id", genericEvent.GetAccountID()))
+		return &notification.ValidationError{EventType: genericEvent.GetType(), Field: "subtype",
+			Reason: fmt.Sprintf("%q is not registered", genericEvent.Subtype), Err: generic.ErrUnknownSubtype}
+	}
+	if err := g.registry.Validate(genericEvent.Subtype, genericEvent.Payload); err != nil {
+		logger.ContextError(ctx, "GenericHandler: invalid payload, dead-letter event",
+			zap.String("subtype", genericEvent.Subtype),
+			zap.Int64("account_id", genericEvent.GetAccountID()),
+			zap.Any("payload", genericEvent.Payload),
+			zap.Error(err))
+		return &notification.ValidationError{EventType: genericEvent.GetType(), Field: "payload", Reason: err.Error(), Err: err}
+	}
+
+	ext := make(map[string]interface{}, len(genericEvent.Payload)+1)
+	for k, v := range genericEvent.Payload {
+		ext[k] = v
+	}
+	ext["subtype"] = genericEvent.Subtype
+	extDataJSON, err := json.Marshal(ext)
+	if err != nil {
+		logger.ContextError(ctx, "GenericHandler: failed to marshal ext data",
+			zap.String("subtype", genericEvent.Subtype),
+			zap.Int64("account_id", genericEvent.GetAccountID()),
+			zap.Error(err))
+		return fmt.Errorf("marshal ext data failed: %w", err)
+	}
+
+	locale := g.locales.Locale(ctx, genericEvent.GetAccountID())
+	key := template.Key{
+		Type:    def.NotifyType,
+		Channel: channel.ChannelInbox,
+		Locale:  locale,
+	}
+	title, content, err := g.templates.Render(key, map[string]interface{}{
+		"Event":   genericEvent,
+		"Payload": genericEvent.Payload,
+		"Ext":     ext,
+	})
+	if err != nil {
+		logger.ContextError(ctx, "GenericHandler: failed to render template",
+			zap.String("subtype", genericEvent.Subtype),
+			zap.Int64("account_id", genericEvent.GetAccountID()),
+			zap.Error(err))
+		return fmt.Errorf("render template failed: %w", err)
+	}
+
+	category := def.Category
+	if category == "" {
+		category = constants.NOTIFICATION_CATEGORY_SYSTEM
+	}
+	// payload中的activity_name用于按活动统计互动
+	activityName, _ := genericEvent.Payload["activity_name"].(string)
+	n := &repo.Notification{
+		AccountID:    event.GetAccountID(),
+		Type:         def.NotifyType,
+		Category:     category,
+		Title:        title,
+		Content:      content,
+		Status:       constants.NOTIFICATION_STATUS_UNREAD,
+		Locale:       locale,
+		ExtData:      string(extDataJSON),
+		ActivityName: activityName,
+		ExpiresAt:    notification.ExpiresAt(event),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	err = g.repo.InsertNotice(ctx, n)
+	if err != nil {
+		logger.ContextError(ctx, "GenericHandler: DATABASE INSERT FAILED - MESSAGE WILL BE LOST",
+			zap.String("event_type", "generic"),
+			zap.String("subtype", genericEvent.Subtype),
+			zap.Int64("account_id", n.AccountID),
+			zap.String("title", n.Title),
+			zap.String("content", n.Content),
+			zap.String("ext_data", n.ExtData),
+			zap.Error(err),
+			zap.String("error_type", fmt.Sprintf("%T", err)),
+			zap.Stack("stack_trace"))
+		return fmt.Errorf("insert notification failed: %w", err)
+	}
+	logger.ContextDebug(ctx, "GenericHandler: notification inserted successfully",
+		zap.String("subtype", genericEvent.Subtype),
+		zap.Int64("account_id", n.AccountID),
+		zap.Uint64("notification_id", n.ID))
+
+	return nil
+}
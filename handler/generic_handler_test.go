@@ -0,0 +1,86 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ethereal3x/notice/generic"
+	"github.com/ethereal3x/notice/handler"
+	"github.com/ethereal3x/notice/i18n"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/template"
+)
+
+func newGenericHandler(t *testing.T, store repo.NotificationStore) *handler.GenericHandler {
+	t.Helper()
+	registry := generic.NewRegistry()
+	err := registry.Register(&generic.Definition{
+		Subtype:    "activity_reminder",
+		NotifyType: 10,
+		Schema:     json.RawMessage(`{"type": "object", "properties": {"activity_name": {"type": "string"}}}`),
+		Templates:  []generic.TemplateDefinition{{Title: "reminder", Content: "{{.Payload.activity_name}}"}},
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	templates := template.NewEngine(registry)
+	if err := templates.Reload(context.Background()); err != nil {
+		t.Fatalf("reload templates: %v", err)
+	}
+	bundle, err := i18n.NewDefaultBundle()
+	if err != nil {
+		t.Fatalf("load bundle: %v", err)
+	}
+	return handler.NewGenericHandler(store, registry, templates, i18n.NewLocalizer(i18n.StaticLocaleResolver(i18n.DefaultLocale), bundle))
+}
+
+func TestGenericHandlerRecordsActivityName(t *testing.T) {
+	ctx := context.Background()
+	store := repo.NewMemoryStore()
+	h := newGenericHandler(t, store)
+
+	if err := h.Handle(notification.NewGenericEvent(ctx, 1, "activity_reminder", map[string]any{"activity_name": "spring"})); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if err := h.Handle(notification.NewGenericEvent(ctx, 2, "activity_reminder", map[string]any{})); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	for accountID, want := range map[int64]string{1: "spring", 2: ""} {
+		notices, err := store.GetNoticesByAccountID(ctx, accountID, nil, 10, 0)
+		if err != nil || len(notices) != 1 {
+			t.Fatalf("GetNoticesByAccountID(%d) = %d notices, %v", accountID, len(notices), err)
+		}
+		if notices[0].ActivityName != want {
+			t.Fatalf("account %d activity name = %q, want %q", accountID, notices[0].ActivityName, want)
+		}
+	}
+}
+
+func TestGenericHandlerDeadLettersInvalidEvents(t *testing.T) {
+	ctx := context.Background()
+	store := repo.NewMemoryStore()
+	h := newGenericHandler(t, store)
+
+	tests := []struct {
+		name  string
+		event *notification.GenericEvent
+		cause error
+	}{
+		{"unknown subtype", notification.NewGenericEvent(ctx, 1, "missing", map[string]any{}), generic.ErrUnknownSubtype},
+		{"invalid payload", notification.NewGenericEvent(ctx, 1, "activity_reminder", map[string]any{"activity_name": 1}), generic.ErrInvalidPayload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := h.Handle(tt.event)
+			if !errors.Is(err, notification.ErrInvalidEvent) || !errors.Is(err, tt.cause) {
+				t.Fatalf("Handle = %v, want ErrInvalidEvent wrapping %v", err, tt.cause)
+			}
+		})
+	}
+	if n, _ := store.GetUnreadCount(ctx, 1); n != 0 {
+		t.Fatalf("unread count = %d, want 0", n)
+	}
+}
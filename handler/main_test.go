@@ -0,0 +1,9 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/ethereal3x/notice/internal/testutil"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
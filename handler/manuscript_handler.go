@@ -6,18 +6,30 @@ import (
 	"time"
 
 	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/channel"
 	"github.com/ethereal3x/notice/constants"
+	"github.com/ethereal3x/notice/i18n"
 	"github.com/ethereal3x/notice/notification"
 	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/template"
 	"go.uber.org/zap"
 )
 
 type ManuscriptHandler struct {
-	repo *repo.NoticeRepository
+	repo         repo.NotificationStore
+	templates    *template.Engine
+	locales      *i18n.Localizer
+	emailEnabled bool
 }
 
-func NewManuscriptHandler(repo *repo.NoticeRepository) *ManuscriptHandler {
-	return &ManuscriptHandler{repo: repo}
+func NewManuscriptHandler(repo repo.NotificationStore, templates *template.Engine, locales *i18n.Localizer) *ManuscriptHandler {
+	return &ManuscriptHandler{repo: repo, templates: templates, locales: locales}
+}
+
+// WithEmail 开启邮件通知
+func (m *ManuscriptHandler) WithEmail() *ManuscriptHandler {
+	m.emailEnabled = true
+	return m
 }
 
 func (m *ManuscriptHandler) SupportEventType() notification.EventType {
@@ -33,8 +45,6 @@ func (m *ManuscriptHandler) Handle(event notification.Event) error {
 	if auditEvent.NewStatus == auditEvent.OldStatus {
 		return nil
 	}
-	title := m.buildTitle(auditEvent)
-	content := m.buildContent(auditEvent)
 	ext := map[string]interface{}{
 		"manuscript_id": auditEvent.ManuscriptId,
 		"old_status":    auditEvent.OldStatus,
@@ -43,6 +53,7 @@ func (m *ManuscriptHandler) Handle(event notification.Event) error {
 		"operate_user":  auditEvent.OperateUser,
 		"activity_name": auditEvent.ActivityName,
 	}
+
 	extDataJSON, err := json.Marshal(ext)
 	if err != nil {
 		logger.ContextError(ctx, "ManuscriptHandler: failed to marshal ext data",
@@ -54,29 +65,42 @@ func (m *ManuscriptHandler) Handle(event notification.Event) error {
 		return fmt.Errorf("marshal ext data failed: %w", err)
 	}
 
-	n := &repo.Notification{
-		AccountID: event.GetAccountID(),
-		Type:      constants.NOTIFICATION_TYPE_MANUSCRIPT_AUDIT,
-		Title:     title,
-		Content:   content,
-		Status:    constants.NOTIFICATION_STATUS_UNREAD,
-		ExtData:   string(extDataJSON),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	locale := m.locales.Locale(ctx, auditEvent.GetAccountID())
+	title, content, err := m.templates.Render(m.templateKey(channel.ChannelInbox, locale), m.templateData(auditEvent, ext))
+	if err != nil {
+		logger.ContextError(ctx, "ManuscriptHandler: failed to render template",
+			zap.String("manuscript_id", auditEvent.ManuscriptId),
+			zap.Int64("account_id", auditEvent.GetAccountID()),
+			zap.Int8("new_status", auditEvent.NewStatus),
+			zap.Error(err))
+		return fmt.Errorf("render template failed: %w", err)
 	}
 
-	// 记录即将插入的通知信息
-	logger.ContextDebug(ctx, "ManuscriptHandler: inserting notification",
-		zap.String("manuscript_id", auditEvent.ManuscriptId),
-		zap.Int64("account_id", n.AccountID),
-		zap.Int8("type", n.Type),
-		zap.String("title", n.Title),
-		zap.String("content", n.Content))
+	n := &repo.Notification{
+		AccountID:    event.GetAccountID(),
+		Type:         constants.NOTIFICATION_TYPE_MANUSCRIPT_AUDIT,
+		Category:     constants.NOTIFICATION_CATEGORY_AUDIT,
+		Title:        title,
+		Content:      content,
+		Status:       constants.NOTIFICATION_STATUS_UNREAD,
+		Locale:       locale,
+		CollapseKey:  auditEvent.GetCollapseKey(),
+		ActivityName: auditEvent.ActivityName,
+		ExtData:      string(extDataJSON),
+		ExpiresAt:    notification.ExpiresAt(event),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
 
-	// 执行数据库插入
-	err = m.repo.InsertNotice(ctx, n)
+	// 同一稿件仍有未读通知时覆盖该通知，历史状态保存在ext_data.history中，查找和覆盖在存储层原子完成
+	superseded, err := m.repo.CollapseNotice(ctx, n, func(prev *repo.Notification) {
+		ext["history"] = m.buildHistory(prev)
+		if data, err := json.Marshal(ext); err == nil {
+			n.ExtData = string(data)
+		}
+	})
 	if err != nil {
-		logger.ContextError(ctx, "ManuscriptHandler: DATABASE INSERT FAILED - MESSAGE WILL BE LOST",
+		logger.ContextError(ctx, "ManuscriptHandler: DATABASE WRITE FAILED - MESSAGE WILL BE LOST",
 			zap.String("event_type", "manuscript_audit"),
 			zap.String("manuscript_id", auditEvent.ManuscriptId),
 			zap.Int64("account_id", n.AccountID),
@@ -91,41 +115,71 @@ func (m *ManuscriptHandler) Handle(event notification.Event) error {
 			zap.Error(err),
 			zap.String("error_type", fmt.Sprintf("%T", err)),
 			zap.Stack("stack_trace"))
-		return fmt.Errorf("insert notification failed: %w", err)
+		return fmt.Errorf("write notification failed: %w", err)
+	}
+	if superseded {
+		logger.ContextDebug(ctx, "ManuscriptHandler: notification superseded",
+			zap.String("manuscript_id", auditEvent.ManuscriptId),
+			zap.Int64("account_id", n.AccountID),
+			zap.Uint64("notification_id", n.ID))
+	} else {
+		logger.ContextDebug(ctx, "ManuscriptHandler: notification inserted successfully",
+			zap.String("manuscript_id", auditEvent.ManuscriptId),
+			zap.Int64("account_id", n.AccountID),
+			zap.Uint64("notification_id", n.ID))
+	}
+
+	if m.emailEnabled {
+		dispatchDelivery(ctx, m.templates, m.templateKey(channel.ChannelEmail, locale), m.templateData(auditEvent, ext), n.AccountID, n.ExpiresAt)
 	}
-	logger.ContextDebug(ctx, "ManuscriptHandler: notification inserted successfully",
-		zap.String("manuscript_id", auditEvent.ManuscriptId),
-		zap.Int64("account_id", n.AccountID),
-		zap.Uint64("notification_id", n.ID))
 
 	return nil
 }
 
-func (m *ManuscriptHandler) buildTitle(event *notification.ManuscriptEvent) string {
-	statusText := m.getStatusText(event.NewStatus)
-	return fmt.Sprintf("稿件审核%s", statusText)
+func (m *ManuscriptHandler) templateKey(ch channel.Channel, locale string) template.Key {
+	return template.Key{
+		Type:    constants.NOTIFICATION_TYPE_MANUSCRIPT_AUDIT,
+		Channel: ch,
+		Locale:  locale,
+	}
 }
 
-func (m *ManuscriptHandler) buildContent(event *notification.ManuscriptEvent) string {
-	statusText := m.getStatusText(event.NewStatus)
-	content := fmt.Sprintf("您在活动【%s】提交的稿件已%s", event.ActivityName, statusText)
-
-	if event.NewStatus == constants.MANUSCRIPT_AUDIT_STATUS_REJECTED && event.AuditReason != "" {
-		content += fmt.Sprintf("，原因：%s", event.AuditReason)
+func (m *ManuscriptHandler) templateData(event *notification.ManuscriptEvent, ext map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"Event":      event,
+		"Ext":        ext,
+		"StatusKey":  m.getStatusKey(event.NewStatus),
+		"IsRejected": event.NewStatus == constants.MANUSCRIPT_AUDIT_STATUS_REJECTED,
 	}
+}
 
-	return content
+// buildHistory 将被覆盖通知的状态追加到其历史记录之后
+func (m *ManuscriptHandler) buildHistory(prev *repo.Notification) []interface{} {
+	var prevExt map[string]interface{}
+	if err := json.Unmarshal([]byte(prev.ExtData), &prevExt); err != nil {
+		prevExt = map[string]interface{}{}
+	}
+	history, _ := prevExt["history"].([]interface{})
+	return append(history, map[string]interface{}{
+		"old_status":   prevExt["old_status"],
+		"new_status":   prevExt["new_status"],
+		"audit_reason": prevExt["audit_reason"],
+		"operate_user": prevExt["operate_user"],
+		"title":        prev.Title,
+		"created_at":   prev.CreatedAt,
+	})
 }
 
-func (m *ManuscriptHandler) getStatusText(status int8) string {
+// getStatusKey 返回审核状态在消息目录中的key
+func (m *ManuscriptHandler) getStatusKey(status int8) string {
 	switch status {
 	case constants.MANUSCRIPT_AUDIT_STATUS_APPROVED:
-		return "审核通过"
+		return "manuscript.status.approved"
 	case constants.MANUSCRIPT_AUDIT_STATUS_REJECTED:
-		return "审核未通过"
+		return "manuscript.status.rejected"
 	case constants.MANUSCRIPT_AUDIT_STATUS_PENDING:
-		return "审核中"
+		return "manuscript.status.pending"
 	default:
-		return "状态更新"
+		return "manuscript.status.unknown"
 	}
 }
@@ -0,0 +1,47 @@
+package handler_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ethereal3x/notice/constants"
+	"github.com/ethereal3x/notice/handler"
+	"github.com/ethereal3x/notice/i18n"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/template"
+)
+
+func TestManuscriptDefaultTemplateShowsReasonOnlyWhenRejected(t *testing.T) {
+	ctx := context.Background()
+	bundle, err := i18n.NewDefaultBundle()
+	if err != nil {
+		t.Fatalf("load bundle: %v", err)
+	}
+	templates := template.NewEngine(template.NewDefaultLoader()).WithTranslator(bundle)
+	if err := templates.Reload(ctx); err != nil {
+		t.Fatalf("reload templates: %v", err)
+	}
+	store := repo.NewMemoryStore()
+	h := handler.NewManuscriptHandler(store, templates, i18n.NewLocalizer(i18n.StaticLocaleResolver(i18n.DefaultLocale), bundle))
+
+	for accountID, status := range map[int64]int8{
+		1: constants.MANUSCRIPT_AUDIT_STATUS_REJECTED,
+		2: constants.MANUSCRIPT_AUDIT_STATUS_APPROVED,
+	} {
+		event := notification.NewManuscriptAuditEvent(ctx, accountID, "MS001", constants.MANUSCRIPT_AUDIT_STATUS_PENDING, status)
+		event.AuditReason = "字数不足"
+		if err := h.Handle(event); err != nil {
+			t.Fatalf("Handle: %v", err)
+		}
+		notices, err := store.GetNoticesByAccountID(ctx, accountID, nil, 10, 0)
+		if err != nil || len(notices) != 1 {
+			t.Fatalf("GetNoticesByAccountID(%d) = %d notices, %v", accountID, len(notices), err)
+		}
+		rejected := status == constants.MANUSCRIPT_AUDIT_STATUS_REJECTED
+		if strings.Contains(notices[0].Content, "字数不足") != rejected {
+			t.Fatalf("status %d content = %q", status, notices[0].Content)
+		}
+	}
+}
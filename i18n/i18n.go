@@ -0,0 +1,187 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed locales/*.json
+var defaultCatalogs embed.FS
+
+// DefaultLocale 默认语言，所有回退链的终点
+const DefaultLocale = "zh-CN"
+
+// Catalog 单个语言的消息目录，key -> fmt格式字符串
+type Catalog map[string]string
+
+// LocaleResolver 查询账号偏好语言
+type LocaleResolver interface {
+	// ResolveLocale 返回账号的语言，例如"en-US"，未知时返回空字符串
+	ResolveLocale(ctx context.Context, accountID int64) (string, error)
+}
+
+// LocaleResolverFunc 函数形式的LocaleResolver
+type LocaleResolverFunc func(ctx context.Context, accountID int64) (string, error)
+
+func (f LocaleResolverFunc) ResolveLocale(ctx context.Context, accountID int64) (string, error) {
+	return f(ctx, accountID)
+}
+
+// StaticLocaleResolver 所有账号使用同一语言
+type StaticLocaleResolver string
+
+func (s StaticLocaleResolver) ResolveLocale(ctx context.Context, accountID int64) (string, error) {
+	return string(s), nil
+}
+
+// Bundle 多语言消息目录集合
+type Bundle struct {
+	catalogs  map[string]Catalog
+	fallbacks map[string][]string
+}
+
+// NewBundle 创建消息目录集合，fallbacks为显式回退配置，例如 "zh-TW" -> ["zh-HK", "zh-CN"]
+func NewBundle(fallbacks map[string][]string) *Bundle {
+	if fallbacks == nil {
+		fallbacks = make(map[string][]string)
+	}
+	return &Bundle{
+		catalogs:  make(map[string]Catalog),
+		fallbacks: fallbacks,
+	}
+}
+
+// NewDefaultBundle 创建加载了内置消息目录的集合
+func NewDefaultBundle() (*Bundle, error) {
+	b := NewBundle(nil)
+	if err := b.LoadFS(defaultCatalogs, "locales"); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// LoadFS 从目录加载 <语言>.json 格式的消息目录，同名key覆盖已有消息
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("read catalog dir failed: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("read catalog %s failed: %w", entry.Name(), err)
+		}
+		var catalog Catalog
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return fmt.Errorf("parse catalog %s failed: %w", entry.Name(), err)
+		}
+		b.AddCatalog(strings.TrimSuffix(entry.Name(), ".json"), catalog)
+	}
+	return nil
+}
+
+// AddCatalog 添加或合并某个语言的消息
+func (b *Bundle) AddCatalog(locale string, catalog Catalog) {
+	existing, ok := b.catalogs[locale]
+	if !ok {
+		existing = make(Catalog, len(catalog))
+		b.catalogs[locale] = existing
+	}
+	for k, v := range catalog {
+		existing[k] = v
+	}
+}
+
+// Chain 返回语言回退链：自身 -> 显式回退 -> 同语种其他地区 -> 默认语言
+func (b *Bundle) Chain(locale string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	add := func(l string) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			chain = append(chain, l)
+		}
+	}
+
+	add(locale)
+	for _, l := range b.fallbacks[locale] {
+		add(l)
+	}
+	if lang := baseLanguage(locale); lang != "" {
+		add(lang)
+		var siblings []string
+		for l := range b.catalogs {
+			if baseLanguage(l) == lang {
+				siblings = append(siblings, l)
+			}
+		}
+		sort.Strings(siblings)
+		for _, l := range siblings {
+			add(l)
+		}
+	}
+	add(DefaultLocale)
+	return chain
+}
+
+// Match 返回回退链上第一个存在消息目录的语言
+func (b *Bundle) Match(locale string) string {
+	for _, l := range b.Chain(locale) {
+		if _, ok := b.catalogs[l]; ok {
+			return l
+		}
+	}
+	return DefaultLocale
+}
+
+// T 翻译消息，按回退链查找，args非空时按fmt格式化；找不到时返回key本身
+func (b *Bundle) T(locale, key string, args ...any) string {
+	for _, l := range b.Chain(locale) {
+		if msg, ok := b.catalogs[l][key]; ok {
+			if len(args) == 0 {
+				return msg
+			}
+			return fmt.Sprintf(msg, args...)
+		}
+	}
+	return key
+}
+
+// baseLanguage 返回语言部分，例如"en-US" -> "en"
+func baseLanguage(locale string) string {
+	lang, _, _ := strings.Cut(locale, "-")
+	return lang
+}
+
+// Localizer 结合账号语言偏好与消息目录确定最终使用的语言
+type Localizer struct {
+	resolver LocaleResolver
+	bundle   *Bundle
+}
+
+func NewLocalizer(resolver LocaleResolver, bundle *Bundle) *Localizer {
+	return &Localizer{resolver: resolver, bundle: bundle}
+}
+
+// Bundle 返回消息目录集合
+func (l *Localizer) Bundle() *Bundle {
+	return l.bundle
+}
+
+// Locale 返回账号实际使用的语言，查询失败时使用默认语言
+func (l *Localizer) Locale(ctx context.Context, accountID int64) string {
+	locale, err := l.resolver.ResolveLocale(ctx, accountID)
+	if err != nil || locale == "" {
+		return l.bundle.Match(DefaultLocale)
+	}
+	return l.bundle.Match(locale)
+}
@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestChainOrder(t *testing.T) {
+	b := NewBundle(map[string][]string{"zh-TW": {"zh-HK"}})
+	for _, locale := range []string{"zh-CN", "zh-HK", "zh-SG", "en-US"} {
+		b.AddCatalog(locale, Catalog{})
+	}
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		// 自身 -> 显式回退 -> 语种 -> 同语种其他地区（按名称排序） -> 默认语言
+		{"zh-TW", []string{"zh-TW", "zh-HK", "zh", "zh-CN", "zh-SG"}},
+		{"en-GB", []string{"en-GB", "en", "en-US", "zh-CN"}},
+		{"", []string{"zh-CN"}},
+	}
+	for _, tt := range tests {
+		if got := b.Chain(tt.locale); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Fatalf("Chain(%q) = %v, want %v", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateFallback(t *testing.T) {
+	b := NewBundle(nil)
+	b.AddCatalog(DefaultLocale, Catalog{"title": "标题", "count": "共%d条"})
+	b.AddCatalog("en-US", Catalog{"title": "Title"})
+
+	tests := []struct {
+		locale, key string
+		args        []any
+		want        string
+	}{
+		{"en-US", "title", nil, "Title"},
+		{"en-GB", "title", nil, "Title"},
+		{"en-US", "count", []any{3}, "共3条"},
+		{"en-US", "missing", nil, "missing"},
+	}
+	for _, tt := range tests {
+		if got := b.T(tt.locale, tt.key, tt.args...); got != tt.want {
+			t.Fatalf("T(%q, %q) = %q, want %q", tt.locale, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLocalizerLocale(t *testing.T) {
+	b, err := NewDefaultBundle()
+	if err != nil {
+		t.Fatalf("NewDefaultBundle: %v", err)
+	}
+	locales := map[int64]string{1: "en-US", 2: "en-GB", 3: "ja-JP"}
+	resolver := LocaleResolverFunc(func(ctx context.Context, accountID int64) (string, error) {
+		if accountID == 4 {
+			return "", errors.New("account service unavailable")
+		}
+		return locales[accountID], nil
+	})
+	l := NewLocalizer(resolver, b)
+	ctx := context.Background()
+	for accountID, want := range map[int64]string{1: "en-US", 2: "en-US", 3: DefaultLocale, 4: DefaultLocale, 5: DefaultLocale} {
+		if got := l.Locale(ctx, accountID); got != want {
+			t.Fatalf("Locale(%d) = %q, want %q", accountID, got, want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+// Package testutil 测试公用的初始化逻辑
+package testutil
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ethereal3x/apc/logger"
+)
+
+// Main 初始化日志后运行测试，日志未初始化时记录日志会panic
+// 测试中会记录日志的包在TestMain中调用：
+//
+//	func TestMain(m *testing.M) { testutil.Main(m) }
+func Main(m *testing.M) {
+	logger.LogInit(logger.Config{Level: logger.LevelError, Format: logger.FormatConsole})
+	os.Exit(m.Run())
+}
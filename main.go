@@ -2,16 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/campaign"
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/generic"
 	"github.com/ethereal3x/notice/handler"
+	"github.com/ethereal3x/notice/i18n"
+	"github.com/ethereal3x/notice/migrate"
 	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/preference"
 	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/retention"
+	"github.com/ethereal3x/notice/segment"
+	"github.com/ethereal3x/notice/template"
+	"github.com/ethereal3x/notice/unread"
 	"gorm.io/gorm"
 )
 
@@ -28,31 +40,156 @@ func main() {
 		logger.ContextError(ctx, fmt.Sprintf("Failed to initialize database: %v", err))
 		os.Exit(1)
 	}
+	shardDBs, err := initShardDBs()
+	if err != nil {
+		logger.ContextError(ctx, fmt.Sprintf("Failed to initialize shard databases: %v", err))
+		os.Exit(1)
+	}
 	logger.ContextInfo(ctx, "Database initialized successfully")
 
+	// migrate子命令只执行表结构迁移，完成后退出
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(ctx, append([]*gorm.DB{db}, shardDBs...), os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	// DB_AUTO_MIGRATE=true 时启动前执行未执行的迁移，多实例同时启动时只有一个实例执行
+	if getEnv("DB_AUTO_MIGRATE", "") == "true" {
+		if err := runMigrate(ctx, append([]*gorm.DB{db}, shardDBs...), []string{"up"}); err != nil {
+			logger.ContextError(ctx, fmt.Sprintf("Failed to migrate database: %v", err))
+			os.Exit(1)
+		}
+	}
+
 	// 3. 初始化仓储层
-	noticeRepo := repo.NewNoticeRepository(db)
+	noticeStore, noticeRepos, err := initNoticeStore(db, shardDBs)
+	if err != nil {
+		logger.ContextError(ctx, fmt.Sprintf("Failed to initialize notice store: %v", err))
+		os.Exit(1)
+	}
+	// 未读数缓存，Redis缓存需接入客户端后通过unread.NewRedisCache追加
+	// 进程内LRU收不到其他副本的增量，TTL默认5秒，限制多副本部署时未读数的滞后时间
+	unreadCounter := unread.NewCounter(noticeStore, unread.NewLRUCache(
+		getEnvAsInt("UNREAD_CACHE_SIZE", 10000),
+		time.Duration(getEnvAsInt("UNREAD_CACHE_TTL_SECONDS", 5))*time.Second,
+	))
+	for _, noticeRepo := range noticeRepos {
+		noticeRepo.WithUnreadObserver(unreadCounter)
+	}
+	unreadCounter.Start(ctx, time.Duration(getEnvAsInt("UNREAD_RECONCILE_SECONDS", 60))*time.Second)
+	// 之后的组件通过缓存读取未读数
+	noticeStore = unread.NewCachedStore(unreadCounter)
 	logger.ContextInfo(ctx, "Repository initialized successfully")
+	if job := initRetention(noticeStore); job != nil {
+		job.Start(ctx, time.Duration(getEnvAsInt("RETENTION_INTERVAL_MINUTES", 60))*time.Minute)
+		logger.ContextInfo(ctx, "Retention job started")
+	}
+
+	// 4. 初始化多语言与通知模板
+	bundle, err := i18n.NewDefaultBundle()
+	if err != nil {
+		logger.ContextError(ctx, fmt.Sprintf("Failed to load message catalogs: %v", err))
+		os.Exit(1)
+	}
+	// 配置了账号服务时按账号查询偏好语言，否则所有账号使用DEFAULT_LOCALE
+	contacts := initContactResolver()
+	defaultLocale := getEnv("DEFAULT_LOCALE", i18n.DefaultLocale)
+	var localeResolver i18n.LocaleResolver = i18n.StaticLocaleResolver(defaultLocale)
+	if contacts != nil {
+		localeResolver = i18n.LocaleResolverFunc(func(ctx context.Context, accountID int64) (string, error) {
+			locale, err := contacts.ResolveLocale(ctx, accountID)
+			if err != nil {
+				logger.ContextWarn(ctx, fmt.Sprintf("Failed to resolve locale of account %d, use default locale: %v", accountID, err))
+			}
+			if err != nil || locale == "" {
+				return defaultLocale, nil
+			}
+			return locale, nil
+		})
+	}
+	locales := i18n.NewLocalizer(localeResolver, bundle)
+	registry := generic.NewRegistry()
+	if dir := getEnv("GENERIC_NOTICE_DIR", ""); dir != "" {
+		if err := registry.LoadDir(dir); err != nil {
+			logger.ContextError(ctx, fmt.Sprintf("Failed to load generic notice definitions: %v", err))
+			os.Exit(1)
+		}
+	}
+	templates, err := initTemplates(ctx, db, bundle, registry)
+	if err != nil {
+		logger.ContextError(ctx, fmt.Sprintf("Failed to initialize templates: %v", err))
+		os.Exit(1)
+	}
+	logger.ContextInfo(ctx, "Templates initialized successfully")
 
-	// 4. 初始化事件分发器并注册处理器
-	dispatcher := notification.NewEventDispatcher(ctx, 1000)
-	dispatcher.RegisterHandler(handler.NewManuscriptHandler(noticeRepo))
-	dispatcher.RegisterHandler(handler.NewAwardHandler(noticeRepo))
+	// 5. 初始化事件分发器并注册处理器
+	// DISPATCH_BATCH_SIZE>1 时worker批量消费，奖励等支持批量处理的事件合并插入
+	// 免打扰等延迟投递的事件保存在主库，重启后由任意实例到期重新入队
+	dispatcher := notification.NewEventDispatcher(ctx, 1000).
+		WithBatchSize(getEnvAsInt("DISPATCH_BATCH_SIZE", 1)).
+		WithPayloadValidator(registry).
+		WithDelayStore(repo.NewDelayedEventRepository(db), time.Duration(getEnvAsInt("DELAY_POLL_SECONDS", 1))*time.Second)
+	dispatcher.Use(
+		preference.Middleware(preference.NewService(repo.NewPreferenceRepository(db)).WithRegistry(registry)),
+		initAggregator(dispatcher).Middleware(),
+		preference.QuietHoursMiddleware(initQuietHours(db), dispatcher),
+	)
+	manuscriptHandler := handler.NewManuscriptHandler(noticeStore, templates, locales)
+	awardHandler := handler.NewAwardHandler(noticeStore, templates, locales)
+	certificationHandler := handler.NewCertificationHandler(noticeStore, templates, locales)
+	digestHandler := handler.NewDigestHandler(noticeStore, templates, locales)
+	var senders []channel.Sender
+	smsSender, err := initSMSSender(contacts)
+	if err != nil {
+		logger.ContextError(ctx, fmt.Sprintf("Failed to initialize SMS channel: %v", err))
+		os.Exit(1)
+	}
+	if smsSender != nil {
+		senders = append(senders, smsSender)
+		awardHandler.WithSMS()
+		logger.ContextInfo(ctx, "SMS channel enabled")
+	}
+	emailSender, err := initEmailSender(contacts)
+	if err != nil {
+		logger.ContextError(ctx, fmt.Sprintf("Failed to initialize email channel: %v", err))
+		os.Exit(1)
+	}
+	if emailSender != nil {
+		senders = append(senders, emailSender)
+		manuscriptHandler.WithEmail()
+		awardHandler.WithEmail()
+		certificationHandler.WithEmail()
+		digestHandler.WithEmail()
+		logger.ContextInfo(ctx, "Email channel enabled")
+	}
+	dispatcher.RegisterHandler(manuscriptHandler)
+	dispatcher.RegisterHandler(awardHandler)
+	dispatcher.RegisterHandler(certificationHandler)
+	dispatcher.RegisterHandler(digestHandler)
+	dispatcher.RegisterHandler(handler.NewGenericHandler(noticeStore, registry, templates, locales))
+	dispatcher.RegisterHandler(handler.NewDeliveryHandler(senders...))
 	dispatcher.Start(5)
 	logger.ContextInfo(ctx, "Event dispatcher initialized successfully")
 
-	// 5. 初始化通知管理器
+	// 6. 初始化通知管理器
 	notification.InitGlobalManager(ctx, dispatcher)
 	logger.ContextInfo(ctx, "Notification manager initialized successfully")
 
-	// 6. 启动完成
+	// 活动推送任务在退出时先于分发器停止，执行中的任务标记为暂停
+	campaignCtx, stopCampaigns := context.WithCancel(ctx)
+	initCampaign(db, dispatcher).Start(campaignCtx, time.Duration(getEnvAsInt("CAMPAIGN_POLL_SECONDS", 10))*time.Second)
+	logger.ContextInfo(ctx, "Campaign job started")
+
+	// 7. 启动完成
 	logger.ContextInfo(ctx, "Notification service started successfully")
 
 	// 测试发送一条通知
 	testNotification(ctx)
 
-	// 7. 等待退出信号
-	waitForShutdown(ctx)
+	// 8. 等待退出信号
+	waitForShutdown(ctx, stopCampaigns)
 }
 
 func initLog() {
@@ -64,13 +201,44 @@ func initLog() {
 }
 
 // initDB 初始化数据库连接
+// DB_DRIVER=sqlite 时使用DB_PATH指定的SQLite文件，用于本地开发
 func initDB() (*gorm.DB, error) {
+	if getEnv("DB_DRIVER", "mysql") == "sqlite" {
+		return openDB(getEnv("DB_PATH", "notice.db"))
+	}
+	return openDB(getEnv("DB_NAME", "notice"))
+}
+
+// initShardDBs 初始化通知分片数据库，未配置DB_SHARDS时不分片
+// DB_SHARDS 为逗号分隔的MySQL库名（与主库共用DB_HOST等连接配置）或SQLite文件路径
+func initShardDBs() ([]*gorm.DB, error) {
+	var dbs []*gorm.DB
+	for _, name := range strings.Split(getEnv("DB_SHARDS", ""), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		db, err := openDB(name)
+		if err != nil {
+			return nil, fmt.Errorf("shard %s: %w", name, err)
+		}
+		dbs = append(dbs, db)
+	}
+	return dbs, nil
+}
+
+// openDB 打开数据库，name为MySQL库名或SQLite文件路径
+func openDB(name string) (*gorm.DB, error) {
+	if getEnv("DB_DRIVER", "mysql") == "sqlite" {
+		return repo.NewSQLiteDB(name)
+	}
+
 	config := &repo.DBConfig{
 		Host:     getEnv("DB_HOST", "localhost"),
 		Port:     getEnvAsInt("DB_PORT", 3306),
 		User:     getEnv("DB_USER", "root"),
 		Password: getEnv("DB_PASSWORD", "123456"),
-		DBName:   getEnv("DB_NAME", "notice"),
+		DBName:   name,
 		Charset:  getEnv("DB_CHARSET", "utf8mb4"),
 	}
 
@@ -82,12 +250,287 @@ func initDB() (*gorm.DB, error) {
 	return db, nil
 }
 
+// initNoticeStore 初始化通知存储，配置了分片库时通知按账号分布到各分片，其余表仍在主库
+// 同时返回底层的各个NoticeRepository，用于设置未读数观察者
+func initNoticeStore(db *gorm.DB, shardDBs []*gorm.DB) (repo.NotificationStore, []*repo.NoticeRepository, error) {
+	if len(shardDBs) == 0 {
+		noticeRepo := repo.NewNoticeRepository(db)
+		return noticeRepo, []*repo.NoticeRepository{noticeRepo}, nil
+	}
+
+	// 多个实例写入同一组分片时，SHARD_NODE_ID需互不相同以保证通知ID唯一，不提供默认值以免各实例都使用0
+	if getEnv("SHARD_NODE_ID", "") == "" {
+		return nil, nil, errors.New("SHARD_NODE_ID is required when DB_SHARDS is set")
+	}
+	nodeID := getEnvAsInt("SHARD_NODE_ID", -1)
+	if nodeID < 0 || nodeID > 1023 {
+		return nil, nil, fmt.Errorf("SHARD_NODE_ID must be between 0 and 1023, got %q", getEnv("SHARD_NODE_ID", ""))
+	}
+
+	noticeRepos := make([]*repo.NoticeRepository, 0, len(shardDBs))
+	shards := make([]repo.NotificationStore, 0, len(shardDBs))
+	for _, shardDB := range shardDBs {
+		noticeRepo := repo.NewNoticeRepository(shardDB)
+		noticeRepos = append(noticeRepos, noticeRepo)
+		shards = append(shards, noticeRepo)
+	}
+
+	// DB_SHARD_STRATEGY=consistent 使用一致性哈希，便于日后增加分片；默认按账号ID取模
+	var (
+		sharder repo.Sharder
+		err     error
+	)
+	if getEnv("DB_SHARD_STRATEGY", "modulo") == "consistent" {
+		sharder, err = repo.NewConsistentHashSharder(len(shards), repo.DefaultVirtualNodes)
+	} else {
+		sharder, err = repo.NewModuloSharder(len(shards))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	store, err := repo.NewShardedStore(sharder, shards...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store.WithNodeID(int64(nodeID)), noticeRepos, nil
+}
+
+// runMigrate 对主库和各分片库执行表结构迁移
+func runMigrate(ctx context.Context, dbs []*gorm.DB, args []string) error {
+	for i, db := range dbs {
+		name := "primary"
+		if i > 0 {
+			name = fmt.Sprintf("shard %d", i-1)
+		}
+		if len(dbs) > 1 {
+			fmt.Printf("== %s ==\n", name)
+		}
+		if err := migrateDB(ctx, db, args); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// migrateDB 执行表结构迁移，args为 up | down [steps] | status，缺省为up
+func migrateDB(ctx context.Context, db *gorm.DB, args []string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	migrator, err := migrate.New(sqlDB, db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	switch action {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		logger.ContextInfo(ctx, fmt.Sprintf("Applied %d migrations", applied))
+		fmt.Printf("applied %d migrations\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if _, err := fmt.Sscanf(args[1], "%d", &steps); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+		}
+		// 遇到不可回滚的版本时，其上的版本已回滚
+		reverted, err := migrator.Down(ctx, steps)
+		logger.ContextInfo(ctx, fmt.Sprintf("Reverted %d migrations", reverted))
+		fmt.Printf("reverted %d migrations\n", reverted)
+		if err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.DateTime)
+			}
+			fmt.Printf("%06d  %-30s  %s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		return fmt.Errorf("unknown action %q, usage: migrate [up | down [steps] | status]", action)
+	}
+	return nil
+}
+
+// initTemplates 初始化模板引擎
+// 加载顺序：内置默认模板 -> 通用通知定义 -> TEMPLATE_DIR目录 -> 数据库（TEMPLATE_DB_ENABLED=true时），后者覆盖前者
+func initTemplates(ctx context.Context, db *gorm.DB, bundle *i18n.Bundle, registry *generic.Registry) (*template.Engine, error) {
+	loaders := []template.Loader{template.NewDefaultLoader(), registry}
+	if dir := getEnv("TEMPLATE_DIR", ""); dir != "" {
+		loaders = append(loaders, template.NewFileLoader(dir))
+	}
+	if getEnv("TEMPLATE_DB_ENABLED", "") == "true" {
+		loaders = append(loaders, template.NewDBLoader(repo.NewTemplateRepository(db)))
+	}
+
+	engine := template.NewEngine(loaders...).WithTranslator(bundle)
+	if err := engine.Reload(ctx); err != nil {
+		return nil, err
+	}
+	engine.Watch(ctx, time.Duration(getEnvAsInt("TEMPLATE_RELOAD_SECONDS", 30))*time.Second)
+	return engine, nil
+}
+
+// initContactResolver 初始化账号联系方式与偏好语言查询，未配置ACCOUNT_CONTACT_URL时返回nil
+func initContactResolver() *channel.HTTPContactResolver {
+	endpoint := getEnv("ACCOUNT_CONTACT_URL", "")
+	if endpoint == "" {
+		return nil
+	}
+	headers := map[string]string{}
+	if token := getEnv("ACCOUNT_CONTACT_TOKEN", ""); token != "" {
+		headers["Authorization"] = token
+	}
+	return channel.NewHTTPContactResolver(channel.HTTPContactConfig{
+		Endpoint: endpoint,
+		Headers:  headers,
+		Timeout:  time.Duration(getEnvAsInt("ACCOUNT_CONTACT_TIMEOUT_MS", 3000)) * time.Millisecond,
+	})
+}
+
+// initSMSSender 初始化短信渠道，未配置服务商时返回nil
+// SMS_HTTP_ENDPOINTS 为逗号分隔的服务商地址，按顺序故障转移；无法查询手机号时短信无法送达，拒绝启用
+func initSMSSender(contacts *channel.HTTPContactResolver) (*channel.SMSSender, error) {
+	endpoints := getEnv("SMS_HTTP_ENDPOINTS", "")
+	if endpoints == "" {
+		return nil, nil
+	}
+	if contacts == nil {
+		return nil, errors.New("SMS_HTTP_ENDPOINTS requires ACCOUNT_CONTACT_URL to look up phone numbers")
+	}
+
+	headers := map[string]string{}
+	if token := getEnv("SMS_HTTP_TOKEN", ""); token != "" {
+		headers["Authorization"] = token
+	}
+
+	var providers []channel.SMSProvider
+	for i, endpoint := range strings.Split(endpoints, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint == "" {
+			continue
+		}
+		providers = append(providers, channel.NewHTTPSMSProvider(channel.HTTPSMSConfig{
+			Name:     fmt.Sprintf("http-%d", i),
+			Endpoint: endpoint,
+			Headers:  headers,
+		}))
+	}
+
+	return channel.NewSMSSender(contacts, channel.SMSConfig{
+		Signature:   getEnv("SMS_SIGNATURE", ""),
+		MaxSegments: getEnvAsInt("SMS_MAX_SEGMENTS", 3),
+	}, providers...), nil
+}
+
+// initEmailSender 初始化邮件渠道，未配置SMTP_ADDR时返回nil；无法查询邮箱时邮件无法送达，拒绝启用
+func initEmailSender(contacts *channel.HTTPContactResolver) (*channel.EmailSender, error) {
+	addr := getEnv("SMTP_ADDR", "")
+	if addr == "" {
+		return nil, nil
+	}
+	if contacts == nil {
+		return nil, errors.New("SMTP_ADDR requires ACCOUNT_CONTACT_URL to look up email addresses")
+	}
+
+	return channel.NewEmailSender(contacts, channel.SMTPConfig{
+		Addr:     addr,
+		Username: getEnv("SMTP_USERNAME", ""),
+		Password: getEnv("SMTP_PASSWORD", ""),
+		From:     getEnv("SMTP_FROM", ""),
+		Timeout:  time.Duration(getEnvAsInt("SMTP_TIMEOUT_SECONDS", 30)) * time.Second,
+	}), nil
+}
+
+// initAggregator 初始化聚合阶段
+// DIGEST_WINDOW_SECONDS>0 时按活动聚合站内信；EMAIL_DIGEST_MODE为hourly/daily时汇总邮件
+func initAggregator(dispatcher *notification.EventDispatcher) *notification.Aggregator {
+	var rules []*notification.AggregateRule
+	if window := getEnvAsInt("DIGEST_WINDOW_SECONDS", 0); window > 0 {
+		rules = append(rules, notification.NewActivityDigestRule(time.Duration(window)*time.Second))
+	}
+	switch getEnv("EMAIL_DIGEST_MODE", "") {
+	case "hourly":
+		rules = append(rules, notification.NewEmailDigestRule(notification.DigestModeHourly, 0))
+	case "daily":
+		rules = append(rules, notification.NewEmailDigestRule(notification.DigestModeDaily, getEnvAsInt("EMAIL_DIGEST_DAILY_HOUR", 9)))
+	}
+	return notification.NewAggregator(dispatcher, rules...)
+}
+
+// initQuietHours 初始化免打扰服务
+// QUIET_HOURS_URGENT_TYPES 为逗号分隔的通知类型，例如 "2,3"，这些类型的外部渠道投递不受免打扰限制
+func initQuietHours(db *gorm.DB) *preference.QuietHoursService {
+	var urgentTypes []int8
+	for _, item := range strings.Split(getEnv("QUIET_HOURS_URGENT_TYPES", ""), ",") {
+		var notifyType int8
+		if _, err := fmt.Sscanf(strings.TrimSpace(item), "%d", &notifyType); err != nil {
+			continue
+		}
+		urgentTypes = append(urgentTypes, notifyType)
+	}
+	return preference.NewQuietHoursService(repo.NewQuietHoursRepository(db), urgentTypes...)
+}
+
+// initCampaign 初始化活动推送任务
+// 分群解析器只能在此处以代码注册，任务通过分群名称引用，查询语句不能来自配置或数据库
+// CAMPAIGN_BATCH_SIZE 为每批分发的账号数，CAMPAIGN_BATCH_INTERVAL_MS 为批次间隔，
+// CAMPAIGN_LEASE_SECONDS 为执行任务的租约时长，执行实例崩溃后任务在租约到期后由其他实例接管
+func initCampaign(db *gorm.DB, dispatcher *notification.EventDispatcher) *campaign.Job {
+	segments := segment.NewRegistry()
+	// TODO: 按业务需要注册分群，例如
+	// segments.Register("spring_contest", segment.NewSQLResolver(db, "SELECT DISTINCT account_id FROM tbl_manuscript WHERE activity_id = ?", 1))
+	campaigns := repo.NewCampaignRepository(db).WithLease(time.Duration(getEnvAsInt("CAMPAIGN_LEASE_SECONDS", int(repo.DefaultCampaignLease/time.Second))) * time.Second)
+	return campaign.NewJob(campaigns, dispatcher, segments).WithThrottle(
+		getEnvAsInt("CAMPAIGN_BATCH_SIZE", 200),
+		time.Duration(getEnvAsInt("CAMPAIGN_BATCH_INTERVAL_MS", 1000))*time.Millisecond,
+	)
+}
+
+// initRetention 初始化通知保留任务，未配置RETENTION_DAYS时返回nil
+// RETENTION_DAYS 格式为逗号分隔的"类型:天数"，例如 "1:180,3:365"，未列出的类型永久保留
+func initRetention(noticeStore repo.NotificationStore) *retention.Job {
+	config := getEnv("RETENTION_DAYS", "")
+	if config == "" {
+		return nil
+	}
+
+	policies := make(map[int8]time.Duration)
+	for _, item := range strings.Split(config, ",") {
+		var notifyType int8
+		var days int
+		if _, err := fmt.Sscanf(strings.TrimSpace(item), "%d:%d", &notifyType, &days); err != nil || days <= 0 {
+			continue
+		}
+		policies[notifyType] = time.Duration(days) * 24 * time.Hour
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	return retention.NewJob(noticeStore, policies).WithBatchSize(getEnvAsInt("RETENTION_BATCH_SIZE", retention.DefaultBatchSize))
+}
+
 // testNotification 测试发送通知
 func testNotification(ctx context.Context) {
 	logger.ContextInfo(ctx, "Testing notification dispatch...")
 
 	// 测试发送稿件审核通知
-	notification.DispatchManuscriptAuditEvent(
+	if err := notification.DispatchManuscriptAuditEvent(
 		ctx,
 		123456,       // accountID
 		"MS001",      // manuscriptID
@@ -96,23 +539,27 @@ func testNotification(ctx context.Context) {
 		"内容质量优秀",     // auditReason
 		"admin",      // operateUser
 		"2024春季征文大赛", // activityName
-	)
+	); err != nil {
+		logger.ContextError(ctx, fmt.Sprintf("Failed to dispatch manuscript audit event: %v", err))
+	}
 
 	// 测试发送奖励通知
-	notification.DispatchAwardEvent(
+	if err := notification.DispatchAwardEvent(
 		ctx,
 		123456,       // accountID
 		"MS001",      // manuscriptID
 		500,          // awardAmount
 		"现金奖励",       // awardType
 		"2024春季征文大赛", // activityName
-	)
+	); err != nil {
+		logger.ContextError(ctx, fmt.Sprintf("Failed to dispatch award event: %v", err))
+	}
 
 	logger.ContextInfo(ctx, "Test notifications dispatched")
 }
 
-// waitForShutdown 等待关闭信号
-func waitForShutdown(ctx context.Context) {
+// waitForShutdown 等待关闭信号，stopJobs用于在分发器停止前停止后台任务
+func waitForShutdown(ctx context.Context, stopJobs context.CancelFunc) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -121,6 +568,7 @@ func waitForShutdown(ctx context.Context) {
 
 	// 优雅关闭
 	logger.ContextInfo(ctx, "Shutting down notification service...")
+	stopJobs()
 	manager := notification.GetGlobalManager()
 	if manager != nil {
 		manager.Stop()
@@ -0,0 +1,425 @@
+// Package migrate 管理数据库表结构的版本化迁移
+//
+// 迁移脚本按方言放在mysql、sqlite目录下并嵌入二进制，文件名格式为{版本号}_{名称}.up.sql和.down.sql，
+// 已执行的版本记录在tbl_schema_migration表中。修改表结构时新增一个版本，不要修改已发布的脚本
+//
+// 版本1是引入迁移前已有的通知表，之后的字段和索引都由独立的版本添加，已有部署可以直接执行up；
+// 版本1没有down脚本，回滚不会删除不是由迁移创建的表
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed mysql/*.sql sqlite/*.sql
+var scripts embed.FS
+
+const (
+	DialectMySQL  = "mysql"
+	DialectSQLite = "sqlite"
+)
+
+// Table 记录已执行迁移的表
+const Table = "tbl_schema_migration"
+
+// DefaultLockTimeout 等待其他实例完成迁移的默认时间
+const DefaultLockTimeout = time.Minute
+
+var (
+	// ErrUnknownDialect 不支持的数据库方言
+	ErrUnknownDialect = errors.New("unknown migration dialect")
+	// ErrLockTimeout 等待迁移锁超时，通常是其他实例正在迁移
+	ErrLockTimeout = errors.New("timed out waiting for migration lock")
+	// ErrUnknownVersion 数据库中记录的版本没有对应的迁移脚本，通常是用旧版本程序回滚了新版本的迁移
+	ErrUnknownVersion = errors.New("applied migration has no script")
+	// ErrIrreversible 迁移没有down脚本，不能回滚，例如接管已有表的基线版本
+	ErrIrreversible = errors.New("migration is irreversible")
+)
+
+// Migration 一个版本的迁移脚本
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string // 为空表示不可回滚
+}
+
+// Status 一个版本的迁移状态
+type Status struct {
+	Version   uint64
+	Name      string
+	AppliedAt *time.Time // 为nil表示未执行
+}
+
+// Load 读取fsys根目录下的迁移脚本，按版本号升序返回
+func Load(fsys fs.FS) ([]*Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, file := range files {
+		name, direction, ok := strings.Cut(strings.TrimSuffix(file, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("migration %s: file name must end with .up.sql or .down.sql", file)
+		}
+		versionText, label, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseUint(versionText, 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("migration %s: file name must start with a positive version number", file)
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, exist := byVersion[version]
+		if !exist {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migration %s: version %d is also named %q", file, version, m.Name)
+		}
+		if direction == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrator 执行迁移，同一时间只允许一个实例迁移
+type Migrator struct {
+	db          *sql.DB
+	dialect     *dialect
+	migrations  []*Migration
+	lockTimeout time.Duration
+}
+
+// New 创建使用内置迁移脚本的Migrator，dialectName为DialectMySQL或DialectSQLite
+func New(db *sql.DB, dialectName string) (*Migrator, error) {
+	d, exist := dialects[dialectName]
+	if !exist {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDialect, dialectName)
+	}
+	sub, err := fs.Sub(scripts, dialectName)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(sub)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: d, migrations: migrations, lockTimeout: DefaultLockTimeout}, nil
+}
+
+// WithLockTimeout 设置等待迁移锁的时间
+func (m *Migrator) WithLockTimeout(timeout time.Duration) *Migrator {
+	if timeout > 0 {
+		m.lockTimeout = timeout
+	}
+	return m
+}
+
+// WithMigrations 替换内置迁移脚本
+func (m *Migrator) WithMigrations(migrations []*Migration) *Migrator {
+	m.migrations = migrations
+	return m
+}
+
+// Up 按版本顺序执行所有未执行的迁移，返回执行的个数
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	var count int
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, exist := applied[migration.Version]; exist {
+				continue
+			}
+			if err := m.exec(ctx, conn, migration, migration.Up); err != nil {
+				return err
+			}
+			_, err := conn.ExecContext(ctx, "INSERT INTO "+Table+" (version, name, applied_at) VALUES (?, ?, ?)",
+				migration.Version, migration.Name, time.Now())
+			if err != nil {
+				return fmt.Errorf("record migration %d: %w", migration.Version, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down 从最新版本开始回滚steps个已执行的迁移，返回回滚的个数
+// 遇到不可回滚的版本时停止，该版本之上的版本已回滚，返回ErrIrreversible；
+// 要回滚的版本中有没有脚本的版本时不执行任何回滚，返回ErrUnknownVersion
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	var (
+		count   int
+		stopped *Migration // 不可回滚而停止的版本
+	)
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]uint64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool {
+			return versions[i] > versions[j]
+		})
+		if len(versions) > steps {
+			versions = versions[:steps]
+		}
+
+		migrations := make([]*Migration, 0, len(versions))
+		for _, version := range versions {
+			migration := m.find(version)
+			if migration == nil {
+				return fmt.Errorf("%w: version %d", ErrUnknownVersion, version)
+			}
+			if migration.Down == "" {
+				stopped = migration
+				break
+			}
+			migrations = append(migrations, migration)
+		}
+
+		for _, migration := range migrations {
+			if err := m.exec(ctx, conn, migration, migration.Down); err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(ctx, "DELETE FROM "+Table+" WHERE version = ?", migration.Version); err != nil {
+				return fmt.Errorf("record rollback of migration %d: %w", migration.Version, err)
+			}
+			count++
+		}
+		return nil
+	})
+	if err == nil && stopped != nil {
+		err = fmt.Errorf("%w: version %d_%s", ErrIrreversible, stopped.Version, stopped.Name)
+	}
+	return count, err
+}
+
+// Status 返回所有已知版本及数据库中已执行版本的状态，按版本号升序排列
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := m.createTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := &Status{Version: migration.Version, Name: migration.Name}
+		if record, exist := applied[migration.Version]; exist {
+			status.AppliedAt = &record.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	// 没有脚本的已执行版本也列出，便于发现版本不一致
+	for _, record := range applied {
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, &Status{Version: record.Version, Name: record.Name, AppliedAt: &appliedAt})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+func (m *Migrator) find(version uint64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+	return nil
+}
+
+// locked 在独占的连接上获取迁移锁后执行fn
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.dialect.lock(ctx, conn, m.lockTimeout); err != nil {
+		return err
+	}
+	err = m.createTable(ctx, conn)
+	if err == nil {
+		err = fn(conn)
+	}
+	if unlockErr := m.dialect.unlock(context.WithoutCancel(ctx), conn, err != nil); err == nil {
+		err = unlockErr
+	}
+	return err
+}
+
+func (m *Migrator) createTable(ctx context.Context, conn *sql.Conn) error {
+	if _, err := conn.ExecContext(ctx, m.dialect.createTable); err != nil {
+		return fmt.Errorf("create %s: %w", Table, err)
+	}
+	return nil
+}
+
+type record struct {
+	Version   uint64
+	Name      string
+	AppliedAt time.Time
+}
+
+// applied 读取已执行的版本
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[uint64]*record, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, applied_at FROM "+Table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make(map[uint64]*record)
+	for rows.Next() {
+		var r record
+		if err := rows.Scan(&r.Version, &r.Name, &r.AppliedAt); err != nil {
+			return nil, err
+		}
+		records[r.Version] = &r
+	}
+	return records, rows.Err()
+}
+
+// exec 逐条执行迁移脚本中的语句
+// MySQL的DDL会隐式提交，脚本中途失败时已执行的语句不会回滚，需要人工修复后重新执行
+func (m *Migrator) exec(ctx context.Context, conn *sql.Conn, migration *Migration, script string) error {
+	for _, statement := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+// splitStatements 按行尾的分号拆分脚本并去掉注释行，驱动默认不允许一次执行多条语句
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    []string
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current = append(current, line)
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		statements = append(statements, strings.Join(current, "\n"))
+	}
+	return statements
+}
+
+// dialect 不同数据库的迁移表结构和加锁方式
+type dialect struct {
+	createTable string
+	lock        func(ctx context.Context, conn *sql.Conn, timeout time.Duration) error
+	// unlock 释放迁移锁，failed表示迁移失败
+	unlock func(ctx context.Context, conn *sql.Conn, failed bool) error
+}
+
+// lockName MySQL命名锁的名称，同一数据库的所有实例共用
+const lockName = "notice_schema_migration"
+
+var dialects = map[string]*dialect{
+	DialectMySQL: {
+		createTable: "CREATE TABLE IF NOT EXISTS `" + Table + "` (" +
+			"`version` bigint unsigned NOT NULL COMMENT '迁移版本号'," +
+			"`name` varchar(255) NOT NULL DEFAULT '' COMMENT '迁移名称'," +
+			"`applied_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '执行时间'," +
+			"PRIMARY KEY (`version`)" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='表结构迁移记录'",
+		// GET_LOCK持有者为当前连接，连接断开时自动释放
+		lock: func(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+			var acquired sql.NullInt64
+			err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(timeout.Seconds())).Scan(&acquired)
+			if err != nil {
+				return fmt.Errorf("acquire migration lock: %w", err)
+			}
+			if acquired.Int64 != 1 {
+				return ErrLockTimeout
+			}
+			return nil
+		},
+		unlock: func(ctx context.Context, conn *sql.Conn, failed bool) error {
+			_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", lockName)
+			return err
+		},
+	},
+	DialectSQLite: {
+		createTable: "CREATE TABLE IF NOT EXISTS `" + Table + "` (" +
+			"`version` integer NOT NULL PRIMARY KEY," +
+			"`name` varchar(255) NOT NULL DEFAULT ''," +
+			"`applied_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP" +
+			")",
+		// SQLite的DDL支持事务，整个迁移在一个写事务中执行，失败时全部回滚
+		lock: func(ctx context.Context, conn *sql.Conn, timeout time.Duration) error {
+			if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA busy_timeout = %d", timeout.Milliseconds())); err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+				return fmt.Errorf("%w: %v", ErrLockTimeout, err)
+			}
+			return nil
+		},
+		unlock: func(ctx context.Context, conn *sql.Conn, failed bool) error {
+			statement := "COMMIT"
+			if failed {
+				statement = "ROLLBACK"
+			}
+			_, err := conn.ExecContext(ctx, statement)
+			return err
+		},
+	},
+}
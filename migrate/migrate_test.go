@@ -0,0 +1,123 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereal3x/notice/migrate"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newSQLiteMigrator(t *testing.T) (*migrate.Migrator, *sql.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql.DB: %v", err)
+	}
+	// 内存数据库的每个连接互相独立
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	migrator, err := migrate.New(sqlDB, migrate.DialectSQLite)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return migrator, sqlDB
+}
+
+// appliedVersions 读取迁移记录表中的版本
+func appliedVersions(t *testing.T, db *sql.DB) []uint64 {
+	t.Helper()
+	rows, err := db.Query("SELECT version FROM " + migrate.Table + " ORDER BY version")
+	if err != nil {
+		t.Fatalf("query versions: %v", err)
+	}
+	defer rows.Close()
+	var versions []uint64
+	for rows.Next() {
+		var v uint64
+		if err := rows.Scan(&v); err != nil {
+			t.Fatalf("scan version: %v", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count); err != nil {
+		t.Fatalf("query sqlite_master: %v", err)
+	}
+	return count > 0
+}
+
+func TestSQLiteUpDownUp(t *testing.T) {
+	ctx := context.Background()
+	migrator, db := newSQLiteMigrator(t)
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	all := make([]uint64, 0, len(statuses))
+	for i, status := range statuses {
+		if status.AppliedAt != nil {
+			t.Fatalf("version %d applied before Up", status.Version)
+		}
+		if status.Version != uint64(i+1) {
+			t.Fatalf("versions are not consecutive: %d at position %d", status.Version, i)
+		}
+		all = append(all, status.Version)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil || applied != len(all) {
+		t.Fatalf("Up = %d, %v, want %d", applied, err, len(all))
+	}
+	if got := appliedVersions(t, db); fmt.Sprint(got) != fmt.Sprint(all) {
+		t.Fatalf("applied versions = %v, want %v", got, all)
+	}
+	if applied, err := migrator.Up(ctx); err != nil || applied != 0 {
+		t.Fatalf("second Up = %d, %v, want 0", applied, err)
+	}
+
+	// 回滚最新的一个版本
+	if reverted, err := migrator.Down(ctx, 1); err != nil || reverted != 1 {
+		t.Fatalf("Down(1) = %d, %v", reverted, err)
+	}
+	if got := appliedVersions(t, db); fmt.Sprint(got) != fmt.Sprint(all[:len(all)-1]) {
+		t.Fatalf("applied versions after Down(1) = %v", got)
+	}
+
+	// 全部回滚时停在不可回滚的版本1
+	reverted, err := migrator.Down(ctx, len(all))
+	if !errors.Is(err, migrate.ErrIrreversible) || reverted != len(all)-2 {
+		t.Fatalf("Down(all) = %d, %v, want %d reverted and ErrIrreversible", reverted, err, len(all)-2)
+	}
+	if got := appliedVersions(t, db); fmt.Sprint(got) != "[1]" {
+		t.Fatalf("applied versions after Down(all) = %v, want [1]", got)
+	}
+	if !tableExists(t, db, "tbl_notification") || tableExists(t, db, "tbl_notification_campaign") {
+		t.Fatal("Down(all) should keep tbl_notification and drop later tables")
+	}
+
+	applied, err = migrator.Up(ctx)
+	if err != nil || applied != len(all)-1 {
+		t.Fatalf("Up after Down = %d, %v, want %d", applied, err, len(all)-1)
+	}
+	if got := appliedVersions(t, db); fmt.Sprint(got) != fmt.Sprint(all) {
+		t.Fatalf("applied versions after Up = %v, want %v", got, all)
+	}
+	if _, err := db.Exec("INSERT INTO tbl_notification_campaign (name, segment, subtype, claimed_until) VALUES ('c', 's', 't', CURRENT_TIMESTAMP)"); err != nil {
+		t.Fatalf("insert into migrated table: %v", err)
+	}
+}
@@ -1,8 +1,5 @@
-create database notice;
-
-use notice;
-
--- 创建通知表
+-- 创建通知表，与引入迁移前docs/notification.sql中的表结构一致
+-- 已有部署中该表已存在，本版本不做修改，只记录为已执行
 CREATE TABLE IF NOT EXISTS `tbl_notification` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
   `account_id` bigint NOT NULL COMMENT '用户账号ID',
@@ -18,4 +15,3 @@ CREATE TABLE IF NOT EXISTS `tbl_notification` (
   KEY `idx_type` (`type`),
   KEY `idx_created_at` (`created_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='通知表';
-
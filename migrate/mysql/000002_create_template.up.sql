@@ -0,0 +1,14 @@
+-- 创建通知模板表
+CREATE TABLE IF NOT EXISTS `tbl_notification_template` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `type` tinyint NOT NULL COMMENT '通知类型: 1-稿件审核 2-认证审核 3-奖励发放 4-汇总',
+  `channel` varchar(32) NOT NULL COMMENT '投递渠道: inbox/sms/email',
+  `locale` varchar(16) NOT NULL COMMENT '语言',
+  `title` varchar(255) NOT NULL COMMENT '标题模板',
+  `content` text NOT NULL COMMENT '内容模板',
+  `enabled` tinyint(1) NOT NULL DEFAULT '1' COMMENT '是否启用',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_type_channel_locale` (`type`, `channel`, `locale`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='通知模板表';
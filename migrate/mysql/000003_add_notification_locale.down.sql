@@ -0,0 +1 @@
+ALTER TABLE `tbl_notification` DROP COLUMN `locale`;
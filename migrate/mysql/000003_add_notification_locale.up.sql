@@ -0,0 +1,3 @@
+-- 记录通知渲染使用的语言
+ALTER TABLE `tbl_notification`
+  ADD COLUMN `locale` varchar(16) NOT NULL DEFAULT '' COMMENT '渲染使用的语言' AFTER `status`;
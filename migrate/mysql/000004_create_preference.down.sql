@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `tbl_notification_quiet_hours`;
+DROP TABLE IF EXISTS `tbl_notification_preference`;
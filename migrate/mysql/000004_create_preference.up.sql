@@ -0,0 +1,27 @@
+-- 创建用户通知偏好表
+CREATE TABLE IF NOT EXISTS `tbl_notification_preference` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `account_id` bigint NOT NULL COMMENT '用户账号ID',
+  `type` tinyint NOT NULL COMMENT '通知类型: 1-稿件审核 2-认证审核 3-奖励发放 4-汇总',
+  `channel` varchar(32) NOT NULL COMMENT '投递渠道',
+  `enabled` tinyint(1) NOT NULL DEFAULT '1' COMMENT '是否接收',
+  `reroute` varchar(32) NOT NULL DEFAULT '' COMMENT '改投渠道，为空表示不改投',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_account_type_channel` (`account_id`, `type`, `channel`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户通知偏好表';
+
+-- 创建免打扰时段表
+CREATE TABLE IF NOT EXISTS `tbl_notification_quiet_hours` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `account_id` bigint NOT NULL COMMENT '用户账号ID',
+  `start_minute` int NOT NULL COMMENT '开始时间(当天分钟数)',
+  `end_minute` int NOT NULL COMMENT '结束时间(当天分钟数)',
+  `timezone` varchar(64) NOT NULL DEFAULT 'Asia/Shanghai' COMMENT '时区',
+  `enabled` tinyint(1) NOT NULL DEFAULT '1' COMMENT '是否启用',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_account` (`account_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='免打扰时段表';
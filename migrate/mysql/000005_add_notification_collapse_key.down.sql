@@ -0,0 +1,3 @@
+ALTER TABLE `tbl_notification`
+  DROP KEY `idx_account_collapse_key`,
+  DROP COLUMN `collapse_key`;
@@ -0,0 +1,4 @@
+-- 折叠键，同一账号下同键的未读通知会被新通知覆盖
+ALTER TABLE `tbl_notification`
+  ADD COLUMN `collapse_key` varchar(128) NOT NULL DEFAULT '' COMMENT '折叠键，同键的未读通知会被新通知覆盖' AFTER `locale`,
+  ADD KEY `idx_account_collapse_key` (`account_id`, `collapse_key`);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `tbl_notification_archive`;
+ALTER TABLE `tbl_notification`
+  DROP KEY `idx_deleted_at`,
+  DROP COLUMN `deleted_at`;
@@ -0,0 +1,24 @@
+-- 通知软删除
+ALTER TABLE `tbl_notification`
+  ADD COLUMN `deleted_at` timestamp NULL DEFAULT NULL COMMENT '删除时间',
+  ADD KEY `idx_deleted_at` (`deleted_at`);
+
+-- 创建通知归档表，超过保留期的通知由保留任务移入
+CREATE TABLE IF NOT EXISTS `tbl_notification_archive` (
+  `id` bigint unsigned NOT NULL COMMENT '原通知ID',
+  `account_id` bigint NOT NULL COMMENT '用户账号ID',
+  `type` tinyint NOT NULL COMMENT '通知类型: 1-稿件审核 2-认证审核 3-奖励发放 4-汇总',
+  `title` varchar(255) NOT NULL COMMENT '通知标题',
+  `content` text NOT NULL COMMENT '通知内容',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态: 0-未读 1-已读',
+  `locale` varchar(16) NOT NULL DEFAULT '' COMMENT '渲染使用的语言',
+  `collapse_key` varchar(128) NOT NULL DEFAULT '' COMMENT '折叠键',
+  `ext_data` text COMMENT '扩展数据(JSON格式)',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '更新时间',
+  `deleted_at` timestamp NULL DEFAULT NULL COMMENT '删除时间',
+  `archived_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '归档时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_account_id` (`account_id`),
+  KEY `idx_archived_at` (`archived_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='通知归档表';
@@ -0,0 +1,5 @@
+-- 限时通知的过期时间
+ALTER TABLE `tbl_notification`
+  ADD COLUMN `expires_at` timestamp NULL DEFAULT NULL COMMENT '过期时间，为空表示不过期' AFTER `updated_at`;
+ALTER TABLE `tbl_notification_archive`
+  ADD COLUMN `expires_at` timestamp NULL DEFAULT NULL COMMENT '过期时间' AFTER `updated_at`;
@@ -0,0 +1,26 @@
+-- 创建广播通知表，广播只存一份，用户读取时合并到个人通知列表
+CREATE TABLE IF NOT EXISTS `tbl_notification_broadcast` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `type` tinyint NOT NULL COMMENT '通知类型',
+  `category` varchar(32) NOT NULL DEFAULT '' COMMENT '通知分类',
+  `title` varchar(255) NOT NULL COMMENT '通知标题',
+  `content` text NOT NULL COMMENT '通知内容',
+  `segment` varchar(64) NOT NULL DEFAULT '' COMMENT '目标用户分群，为空表示所有用户',
+  `ext_data` text COMMENT '扩展数据(JSON格式)',
+  `expires_at` timestamp NULL DEFAULT NULL COMMENT '过期时间，为空表示不过期',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  `deleted_at` timestamp NULL DEFAULT NULL COMMENT '删除时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_segment_created_at` (`segment`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='广播通知表';
+
+-- 创建广播已读表，用户首次读取广播时写入
+CREATE TABLE IF NOT EXISTS `tbl_notification_broadcast_read` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `account_id` bigint NOT NULL COMMENT '用户账号ID',
+  `broadcast_id` bigint unsigned NOT NULL COMMENT '广播ID',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '读取时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_account_broadcast` (`account_id`, `broadcast_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='广播已读表';
@@ -0,0 +1,15 @@
+-- 创建活动推送任务表，按分群分批推送并记录进度
+CREATE TABLE IF NOT EXISTS `tbl_notification_campaign` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `name` varchar(128) NOT NULL COMMENT '任务名称',
+  `segment` varchar(64) NOT NULL COMMENT '目标分群',
+  `subtype` varchar(64) NOT NULL COMMENT '通用通知子类型',
+  `payload` text COMMENT '通知参数(JSON格式)',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态: 0-待执行 1-执行中 2-已暂停 3-已完成 4-失败',
+  `last_account_id` bigint NOT NULL DEFAULT '0' COMMENT '已分发的最后一个账号ID',
+  `dispatched` bigint NOT NULL DEFAULT '0' COMMENT '已分发的账号数',
+  `last_error` varchar(512) NOT NULL DEFAULT '' COMMENT '最近一次失败原因',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='活动推送任务表';
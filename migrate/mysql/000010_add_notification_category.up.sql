@@ -0,0 +1,5 @@
+-- 通知分类，用于按分类统计未读数
+ALTER TABLE `tbl_notification`
+  ADD COLUMN `category` varchar(32) NOT NULL DEFAULT '' COMMENT '通知分类: audit-审核 reward-奖励 system-系统' AFTER `type`;
+ALTER TABLE `tbl_notification_archive`
+  ADD COLUMN `category` varchar(32) NOT NULL DEFAULT '' COMMENT '通知分类: audit-审核 reward-奖励 system-系统' AFTER `type`;
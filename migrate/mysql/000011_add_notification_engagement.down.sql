@@ -0,0 +1,10 @@
+ALTER TABLE `tbl_notification_archive`
+  DROP COLUMN `dismissed_at`,
+  DROP COLUMN `clicked_at`,
+  DROP COLUMN `read_at`,
+  DROP COLUMN `activity_name`;
+ALTER TABLE `tbl_notification`
+  DROP COLUMN `dismissed_at`,
+  DROP COLUMN `clicked_at`,
+  DROP COLUMN `read_at`,
+  DROP COLUMN `activity_name`;
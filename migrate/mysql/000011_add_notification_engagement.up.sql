@@ -0,0 +1,11 @@
+-- 按活动统计阅读、点击和忽略
+ALTER TABLE `tbl_notification`
+  ADD COLUMN `activity_name` varchar(128) NOT NULL DEFAULT '' COMMENT '关联的活动名称，用于按活动统计' AFTER `collapse_key`,
+  ADD COLUMN `read_at` timestamp NULL DEFAULT NULL COMMENT '首次阅读时间' AFTER `updated_at`,
+  ADD COLUMN `clicked_at` timestamp NULL DEFAULT NULL COMMENT '首次点击时间' AFTER `read_at`,
+  ADD COLUMN `dismissed_at` timestamp NULL DEFAULT NULL COMMENT '首次忽略时间' AFTER `clicked_at`;
+ALTER TABLE `tbl_notification_archive`
+  ADD COLUMN `activity_name` varchar(128) NOT NULL DEFAULT '' COMMENT '关联的活动名称' AFTER `collapse_key`,
+  ADD COLUMN `read_at` timestamp NULL DEFAULT NULL COMMENT '首次阅读时间' AFTER `updated_at`,
+  ADD COLUMN `clicked_at` timestamp NULL DEFAULT NULL COMMENT '首次点击时间' AFTER `read_at`,
+  ADD COLUMN `dismissed_at` timestamp NULL DEFAULT NULL COMMENT '首次忽略时间' AFTER `clicked_at`;
@@ -0,0 +1,11 @@
+-- 创建延迟事件表，免打扰时段内的外部渠道投递保存到时段结束后重新入队，重启后不丢失
+CREATE TABLE IF NOT EXISTS `tbl_notification_delayed` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `account_id` bigint NOT NULL COMMENT '用户账号ID',
+  `payload` blob NOT NULL COMMENT '序列化后的事件',
+  `due_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '到期时间',
+  `claimed_until` timestamp NULL DEFAULT NULL COMMENT '被实例取出后的租约到期时间，到期未完成时可被重新取出',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_due_at` (`due_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='延迟事件表';
@@ -0,0 +1 @@
+ALTER TABLE `tbl_notification_campaign` DROP COLUMN `claimed_until`;
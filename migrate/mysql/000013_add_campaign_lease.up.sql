@@ -0,0 +1,3 @@
+-- 活动推送任务的租约，执行中的实例崩溃后租约到期，任务可被其他实例接管
+ALTER TABLE `tbl_notification_campaign`
+  ADD COLUMN `claimed_until` timestamp NULL DEFAULT NULL COMMENT '执行实例的租约到期时间，到期后可被其他实例接管' AFTER `last_error`;
@@ -0,0 +1,15 @@
+-- 创建通知表，与引入迁移前的表结构一致
+CREATE TABLE IF NOT EXISTS `tbl_notification` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `account_id` integer NOT NULL,
+  `type` integer NOT NULL,
+  `title` varchar(255) NOT NULL,
+  `content` text NOT NULL,
+  `status` integer NOT NULL DEFAULT 0,
+  `ext_data` text,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS `idx_notification_account_status` ON `tbl_notification` (`account_id`, `status`);
+CREATE INDEX IF NOT EXISTS `idx_notification_type` ON `tbl_notification` (`type`);
+CREATE INDEX IF NOT EXISTS `idx_notification_created_at` ON `tbl_notification` (`created_at`);
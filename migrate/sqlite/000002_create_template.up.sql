@@ -0,0 +1,13 @@
+-- 创建通知模板表
+CREATE TABLE IF NOT EXISTS `tbl_notification_template` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `type` integer NOT NULL,
+  `channel` varchar(32) NOT NULL,
+  `locale` varchar(16) NOT NULL,
+  `title` varchar(255) NOT NULL,
+  `content` text NOT NULL,
+  `enabled` numeric NOT NULL DEFAULT 1,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `uk_template_type_channel_locale` ON `tbl_notification_template` (`type`, `channel`, `locale`);
@@ -0,0 +1,2 @@
+-- 记录通知渲染使用的语言
+ALTER TABLE `tbl_notification` ADD COLUMN `locale` varchar(16) NOT NULL DEFAULT '';
@@ -0,0 +1,25 @@
+-- 创建用户通知偏好表
+CREATE TABLE IF NOT EXISTS `tbl_notification_preference` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `account_id` integer NOT NULL,
+  `type` integer NOT NULL,
+  `channel` varchar(32) NOT NULL,
+  `enabled` numeric NOT NULL DEFAULT 1,
+  `reroute` varchar(32) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `uk_account_type_channel` ON `tbl_notification_preference` (`account_id`, `type`, `channel`);
+
+-- 创建免打扰时段表
+CREATE TABLE IF NOT EXISTS `tbl_notification_quiet_hours` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `account_id` integer NOT NULL,
+  `start_minute` integer NOT NULL,
+  `end_minute` integer NOT NULL,
+  `timezone` varchar(64) NOT NULL DEFAULT 'Asia/Shanghai',
+  `enabled` numeric NOT NULL DEFAULT 1,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `uk_account` ON `tbl_notification_quiet_hours` (`account_id`);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_notification_account_collapse_key`;
+ALTER TABLE `tbl_notification` DROP COLUMN `collapse_key`;
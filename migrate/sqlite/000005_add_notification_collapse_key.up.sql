@@ -0,0 +1,3 @@
+-- 折叠键，同一账号下同键的未读通知会被新通知覆盖
+ALTER TABLE `tbl_notification` ADD COLUMN `collapse_key` varchar(128) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS `idx_notification_account_collapse_key` ON `tbl_notification` (`account_id`, `collapse_key`);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `tbl_notification_archive`;
+ALTER TABLE `tbl_notification` DROP COLUMN `deleted_at`;
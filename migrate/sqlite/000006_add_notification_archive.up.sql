@@ -0,0 +1,21 @@
+-- 通知软删除
+ALTER TABLE `tbl_notification` ADD COLUMN `deleted_at` datetime;
+
+-- 创建通知归档表，超过保留期的通知由保留任务移入
+CREATE TABLE IF NOT EXISTS `tbl_notification_archive` (
+  `id` integer NOT NULL PRIMARY KEY,
+  `account_id` integer NOT NULL,
+  `type` integer NOT NULL,
+  `title` varchar(255) NOT NULL,
+  `content` text NOT NULL,
+  `status` integer NOT NULL DEFAULT 0,
+  `locale` varchar(16) NOT NULL DEFAULT '',
+  `collapse_key` varchar(128) NOT NULL DEFAULT '',
+  `ext_data` text,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `deleted_at` datetime,
+  `archived_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS `idx_notification_archive_account_id` ON `tbl_notification_archive` (`account_id`);
+CREATE INDEX IF NOT EXISTS `idx_notification_archive_archived_at` ON `tbl_notification_archive` (`archived_at`);
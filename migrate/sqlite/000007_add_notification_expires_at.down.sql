@@ -0,0 +1,2 @@
+ALTER TABLE `tbl_notification_archive` DROP COLUMN `expires_at`;
+ALTER TABLE `tbl_notification` DROP COLUMN `expires_at`;
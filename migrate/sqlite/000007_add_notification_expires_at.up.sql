@@ -0,0 +1,3 @@
+-- 限时通知的过期时间
+ALTER TABLE `tbl_notification` ADD COLUMN `expires_at` datetime;
+ALTER TABLE `tbl_notification_archive` ADD COLUMN `expires_at` datetime;
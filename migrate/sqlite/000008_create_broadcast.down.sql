@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `tbl_notification_broadcast_read`;
+DROP TABLE IF EXISTS `tbl_notification_broadcast`;
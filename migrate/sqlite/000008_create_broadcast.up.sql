@@ -0,0 +1,24 @@
+-- 创建广播通知表，广播只存一份，用户读取时合并到个人通知列表
+CREATE TABLE IF NOT EXISTS `tbl_notification_broadcast` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `type` integer NOT NULL,
+  `category` varchar(32) NOT NULL DEFAULT '',
+  `title` varchar(255) NOT NULL,
+  `content` text NOT NULL,
+  `segment` varchar(64) NOT NULL DEFAULT '',
+  `ext_data` text,
+  `expires_at` datetime,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `deleted_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_broadcast_segment_created_at` ON `tbl_notification_broadcast` (`segment`, `created_at`);
+
+-- 创建广播已读表，用户首次读取广播时写入
+CREATE TABLE IF NOT EXISTS `tbl_notification_broadcast_read` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `account_id` integer NOT NULL,
+  `broadcast_id` integer NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `uk_account_broadcast` ON `tbl_notification_broadcast_read` (`account_id`, `broadcast_id`);
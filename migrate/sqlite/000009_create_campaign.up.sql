@@ -0,0 +1,14 @@
+-- 创建活动推送任务表，按分群分批推送并记录进度
+CREATE TABLE IF NOT EXISTS `tbl_notification_campaign` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `name` varchar(128) NOT NULL,
+  `segment` varchar(64) NOT NULL,
+  `subtype` varchar(64) NOT NULL,
+  `payload` text,
+  `status` integer NOT NULL DEFAULT 0,
+  `last_account_id` integer NOT NULL DEFAULT 0,
+  `dispatched` integer NOT NULL DEFAULT 0,
+  `last_error` varchar(512) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+ALTER TABLE `tbl_notification_archive` DROP COLUMN `category`;
+ALTER TABLE `tbl_notification` DROP COLUMN `category`;
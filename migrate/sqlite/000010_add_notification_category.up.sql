@@ -0,0 +1,3 @@
+-- 通知分类，用于按分类统计未读数
+ALTER TABLE `tbl_notification` ADD COLUMN `category` varchar(32) NOT NULL DEFAULT '';
+ALTER TABLE `tbl_notification_archive` ADD COLUMN `category` varchar(32) NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+ALTER TABLE `tbl_notification_archive` DROP COLUMN `dismissed_at`;
+ALTER TABLE `tbl_notification_archive` DROP COLUMN `clicked_at`;
+ALTER TABLE `tbl_notification_archive` DROP COLUMN `read_at`;
+ALTER TABLE `tbl_notification_archive` DROP COLUMN `activity_name`;
+ALTER TABLE `tbl_notification` DROP COLUMN `dismissed_at`;
+ALTER TABLE `tbl_notification` DROP COLUMN `clicked_at`;
+ALTER TABLE `tbl_notification` DROP COLUMN `read_at`;
+ALTER TABLE `tbl_notification` DROP COLUMN `activity_name`;
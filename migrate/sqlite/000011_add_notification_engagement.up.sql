@@ -0,0 +1,9 @@
+-- 按活动统计阅读、点击和忽略
+ALTER TABLE `tbl_notification` ADD COLUMN `activity_name` varchar(128) NOT NULL DEFAULT '';
+ALTER TABLE `tbl_notification` ADD COLUMN `read_at` datetime;
+ALTER TABLE `tbl_notification` ADD COLUMN `clicked_at` datetime;
+ALTER TABLE `tbl_notification` ADD COLUMN `dismissed_at` datetime;
+ALTER TABLE `tbl_notification_archive` ADD COLUMN `activity_name` varchar(128) NOT NULL DEFAULT '';
+ALTER TABLE `tbl_notification_archive` ADD COLUMN `read_at` datetime;
+ALTER TABLE `tbl_notification_archive` ADD COLUMN `clicked_at` datetime;
+ALTER TABLE `tbl_notification_archive` ADD COLUMN `dismissed_at` datetime;
@@ -0,0 +1,10 @@
+-- 创建延迟事件表，免打扰时段内的外部渠道投递保存到时段结束后重新入队，重启后不丢失
+CREATE TABLE IF NOT EXISTS `tbl_notification_delayed` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `account_id` integer NOT NULL,
+  `payload` blob NOT NULL,
+  `due_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `claimed_until` datetime,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS `idx_delayed_due_at` ON `tbl_notification_delayed` (`due_at`);
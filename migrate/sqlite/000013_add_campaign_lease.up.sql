@@ -0,0 +1,2 @@
+-- 活动推送任务的租约，执行中的实例崩溃后租约到期，任务可被其他实例接管
+ALTER TABLE `tbl_notification_campaign` ADD COLUMN `claimed_until` datetime;
@@ -0,0 +1,254 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/constants"
+	"go.uber.org/zap"
+)
+
+// DigestMode 聚合缓冲的刷新模式
+type DigestMode int
+
+const (
+	DigestModeWindow DigestMode = iota // 首条事件到达后经过固定窗口刷新
+	DigestModeHourly                   // 每个整点刷新
+	DigestModeDaily                    // 每天固定时刻刷新
+)
+
+// AggregateRule 聚合规则
+type AggregateRule struct {
+	Name      string        // 规则名称
+	Mode      DigestMode    // 刷新模式
+	Window    time.Duration // DigestModeWindow下的窗口长度
+	DailyHour int           // DigestModeDaily下的刷新时刻(0-23，本地时间)
+
+	// Key 返回事件的聚合键，ok为false表示事件不适用该规则
+	Key func(event Event) (key string, ok bool)
+
+	// Merge 将同一账号同一聚合键下的多条事件合并为一条
+	Merge func(key string, events []Event) Event
+}
+
+// nextFlush 计算缓冲的刷新时间
+func (r *AggregateRule) nextFlush(now time.Time) time.Time {
+	switch r.Mode {
+	case DigestModeHourly:
+		return now.Truncate(time.Hour).Add(time.Hour)
+	case DigestModeDaily:
+		local := now.Local()
+		at := time.Date(local.Year(), local.Month(), local.Day(), r.DailyHour, 0, 0, 0, time.Local)
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at
+	default:
+		return now.Add(r.Window)
+	}
+}
+
+type bucketKey struct {
+	rule    string
+	account int64
+	key     string
+}
+
+type bucket struct {
+	rule   *AggregateRule
+	events []Event
+	timer  *time.Timer
+}
+
+// Aggregator 聚合阶段，按账号+聚合键缓冲事件，到期后合并为一条重新分发
+// 缓冲保存在进程内，分发器Stop时在调用方goroutine中同步处理全部缓冲，不经过即将关闭的队列
+type Aggregator struct {
+	dispatcher *EventDispatcher
+	rules      []*AggregateRule
+	mu         sync.Mutex
+	buckets    map[bucketKey]*bucket
+}
+
+// NewAggregator 创建聚合器，并在分发器停止时刷出全部缓冲
+func NewAggregator(dispatcher *EventDispatcher, rules ...*AggregateRule) *Aggregator {
+	a := &Aggregator{
+		dispatcher: dispatcher,
+		rules:      rules,
+		buckets:    make(map[bucketKey]*bucket),
+	}
+	dispatcher.OnStop(a.flushOnStop)
+	return a
+}
+
+// Middleware 返回聚合中间件，命中规则的事件进入缓冲，不再继续处理，返回ErrDeferred
+func (a *Aggregator) Middleware() Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(event Event) error {
+			if aggregated, ok := event.(interface{ isAggregated() bool }); ok && aggregated.isAggregated() {
+				return next(event)
+			}
+			for _, rule := range a.rules {
+				key, ok := rule.Key(event)
+				if !ok {
+					continue
+				}
+				a.add(rule, key, event)
+				return ErrDeferred
+			}
+			return next(event)
+		}
+	}
+}
+
+func (a *Aggregator) add(rule *AggregateRule, key string, event Event) {
+	k := bucketKey{rule: rule.Name, account: event.GetAccountID(), key: key}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	b, exist := a.buckets[k]
+	if !exist {
+		b = &bucket{rule: rule}
+		delay := time.Until(rule.nextFlush(time.Now()))
+		b.timer = time.AfterFunc(delay, func() { a.flush(k, a.dispatcher.Dispatch) })
+		a.buckets[k] = b
+	}
+	b.events = append(b.events, event)
+
+	logger.ContextDebug(event.GetContext(), "Aggregator: event buffered",
+		zap.String("rule", rule.Name),
+		zap.String("key", key),
+		zap.Int64("account_id", event.GetAccountID()),
+		zap.Int("buffered", len(b.events)))
+}
+
+// Flush 立即刷出全部缓冲，合并后的事件重新入队
+func (a *Aggregator) Flush() {
+	a.flushAll(a.dispatcher.Dispatch)
+}
+
+// flushOnStop 分发器停止时刷出全部缓冲，此时worker即将退出，合并后的事件直接同步处理
+func (a *Aggregator) flushOnStop() {
+	a.flushAll(a.dispatcher.DispatchSync)
+}
+
+func (a *Aggregator) flushAll(dispatch func(Event) error) {
+	a.mu.Lock()
+	keys := make([]bucketKey, 0, len(a.buckets))
+	for k, b := range a.buckets {
+		b.timer.Stop()
+		keys = append(keys, k)
+	}
+	a.mu.Unlock()
+
+	for _, k := range keys {
+		a.flush(k, dispatch)
+	}
+}
+
+func (a *Aggregator) flush(k bucketKey, dispatch func(Event) error) {
+	a.mu.Lock()
+	b, exist := a.buckets[k]
+	delete(a.buckets, k)
+	a.mu.Unlock()
+	if !exist || len(b.events) == 0 {
+		return
+	}
+
+	event := b.events[0]
+	if len(b.events) > 1 {
+		event = b.rule.Merge(k.key, b.events)
+	}
+	if marker, ok := event.(interface{ markAggregated() }); ok {
+		marker.markAggregated()
+	}
+
+	logger.ContextDebug(event.GetContext(), "Aggregator: flush buffered events",
+		zap.String("rule", k.rule),
+		zap.String("key", k.key),
+		zap.Int64("account_id", k.account),
+		zap.Int("count", len(b.events)))
+	if err := dispatch(event); err != nil {
+		logger.ContextError(event.GetContext(), "Aggregator: failed to dispatch flushed events",
+			zap.String("rule", k.rule),
+			zap.String("key", k.key),
+			zap.Int64("account_id", k.account),
+			zap.Int("count", len(b.events)),
+			zap.Error(err))
+	}
+}
+
+// NewActivityDigestRule 按账号+活动名称聚合稿件审核和奖励发放事件，合并为DigestEvent
+func NewActivityDigestRule(window time.Duration) *AggregateRule {
+	return &AggregateRule{
+		Name:   "activity_digest",
+		Mode:   DigestModeWindow,
+		Window: window,
+		Key: func(event Event) (string, bool) {
+			switch e := event.(type) {
+			case *ManuscriptEvent:
+				return e.ActivityName, e.ActivityName != ""
+			case *AwardEvent:
+				return e.ActivityName, e.ActivityName != ""
+			default:
+				return "", false
+			}
+		},
+		Merge: func(key string, events []Event) Event {
+			digest := &DigestEvent{
+				BaseEvent: BaseEvent{
+					ID:      newEventID(),
+					Type:    EventTypeDigest,
+					Account: events[0].GetAccountID(),
+					Ctx:     events[0].GetContext(),
+					Time:    time.Now(),
+				},
+				Key:          key,
+				ActivityName: key,
+			}
+			for _, event := range events {
+				switch e := event.(type) {
+				case *ManuscriptEvent:
+					digest.Manuscripts = append(digest.Manuscripts, e)
+				case *AwardEvent:
+					digest.Awards = append(digest.Awards, e)
+				}
+			}
+			return digest
+		},
+	}
+}
+
+// NewEmailDigestRule 按小时或按天汇总同一账号的邮件投递，合并为一封邮件
+func NewEmailDigestRule(mode DigestMode, dailyHour int) *AggregateRule {
+	return &AggregateRule{
+		Name:      "email_digest",
+		Mode:      mode,
+		DailyHour: dailyHour,
+		Key: func(event Event) (string, bool) {
+			e, ok := event.(*DeliveryEvent)
+			if !ok || e.Channel != channel.ChannelEmail {
+				return "", false
+			}
+			return string(channel.ChannelEmail), true
+		},
+		Merge: func(key string, events []Event) Event {
+			sections := make([]string, 0, len(events))
+			var first *DeliveryEvent
+			for _, event := range events {
+				e := event.(*DeliveryEvent)
+				if first == nil {
+					first = e
+				}
+				sections = append(sections, e.Title+"\n"+e.Content)
+			}
+			return NewDeliveryEvent(first.GetContext(), first.GetAccountID(), channel.ChannelEmail,
+				constants.NOTIFICATION_TYPE_DIGEST,
+				fmt.Sprintf("(%d) %s", len(events), first.Title),
+				strings.Join(sections, "\n\n"))
+		},
+	}
+}
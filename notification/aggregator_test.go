@@ -0,0 +1,53 @@
+package notification_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereal3x/notice/notification"
+)
+
+// digestRecorder 记录收到的汇总事件
+type digestRecorder struct {
+	events []*notification.DigestEvent
+}
+
+func (h *digestRecorder) Handle(event notification.Event) error {
+	h.events = append(h.events, event.(*notification.DigestEvent))
+	return nil
+}
+
+func (h *digestRecorder) SupportEventType() notification.EventType {
+	return notification.EventTypeDigest
+}
+
+func TestAggregatorHandlesBufferedEventsOnStop(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := notification.NewEventDispatcher(ctx, 10)
+	aggregator := notification.NewAggregator(dispatcher, notification.NewActivityDigestRule(time.Hour))
+	dispatcher.Use(aggregator.Middleware())
+	recorder := &digestRecorder{}
+	dispatcher.RegisterHandler(recorder)
+	dispatcher.Start(1)
+
+	for _, manuscriptID := range []string{"MS001", "MS002"} {
+		event := notification.NewAwardEvent(ctx, 1, manuscriptID, 100, "cash")
+		event.ActivityName = "spring"
+		receipt, err := dispatcher.DispatchWithReceipt(event)
+		if err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+		if err := receipt.Wait(ctx); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	if len(recorder.events) != 0 {
+		t.Fatalf("digest handled before the window closed")
+	}
+
+	dispatcher.Stop()
+	if len(recorder.events) != 1 || len(recorder.events[0].Awards) != 2 {
+		t.Fatalf("digests = %+v, want one digest with both awards", recorder.events)
+	}
+}
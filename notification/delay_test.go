@@ -0,0 +1,62 @@
+package notification_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/constants"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/repo"
+)
+
+// deliveryRecorder 记录收到的投递事件
+type deliveryRecorder struct {
+	events chan *notification.DeliveryEvent
+}
+
+func (h *deliveryRecorder) Handle(event notification.Event) error {
+	h.events <- event.(*notification.DeliveryEvent)
+	return nil
+}
+
+func (h *deliveryRecorder) SupportEventType() notification.EventType {
+	return notification.EventTypeDelivery
+}
+
+func TestDispatchAfterSurvivesRestart(t *testing.T) {
+	db, err := repo.NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	store := repo.NewDelayedEventRepository(db)
+	ctx := context.Background()
+
+	first := notification.NewEventDispatcher(ctx, 10).WithDelayStore(store, 10*time.Millisecond)
+	first.Start(1)
+	event := notification.NewDeliveryEvent(ctx, 42, channel.ChannelSMS, constants.NOTIFICATION_TYPE_REWARD_DISTRIBUTE, "title", "content")
+	if err := first.DispatchAfter(event, 200*time.Millisecond); err != nil {
+		t.Fatalf("dispatch after: %v", err)
+	}
+	// 模拟在事件到期前重启
+	first.Stop()
+
+	recorder := &deliveryRecorder{events: make(chan *notification.DeliveryEvent, 1)}
+	second := notification.NewEventDispatcher(ctx, 10).WithDelayStore(store, 10*time.Millisecond)
+	second.RegisterHandler(recorder)
+	second.Start(1)
+	defer second.Stop()
+
+	select {
+	case got := <-recorder.events:
+		if got.GetAccountID() != 42 || got.Channel != channel.ChannelSMS || got.Content != "content" {
+			t.Fatalf("redispatched event = %+v", got)
+		}
+		if got.GetContext() == nil {
+			t.Fatal("redispatched event has no context")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("delayed event was not redispatched after restart")
+	}
+}
@@ -2,6 +2,8 @@ package notification
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -9,15 +11,53 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrDispatcherClosed 分发器已停止
+var ErrDispatcherClosed = errors.New("event dispatcher is closed")
+
+// DefaultDelayPollInterval 默认每隔多久从延迟事件存储中取出到期的事件
+const DefaultDelayPollInterval = time.Second
+
+// delayClaimBatch 每次从延迟事件存储中取出的事件数
+const delayClaimBatch = 100
+
+// DelayStore 延迟事件存储，保存序列化后的事件直到到期
+// 多个实例共用一个存储时，ClaimDelayed需保证同一事件只交给一个实例
+type DelayStore interface {
+	// SaveDelayed 保存事件，dueAt后可被取出
+	SaveDelayed(ctx context.Context, accountID int64, payload []byte, dueAt time.Time) error
+	// ClaimDelayed 取出最多limit个已到期的事件交给fn，fn返回nil后删除，返回错误时保留等待重试，返回成功处理的个数
+	ClaimDelayed(ctx context.Context, now time.Time, limit int, fn func(payload []byte) error) (int, error)
+}
+
+// HandleFunc 事件处理函数
+type HandleFunc func(event Event) error
+
+// Middleware 事件处理中间件，在路由到handler之前执行，可跳过、改写事件
+// 中间件不调用next即表示跳过该事件
+type Middleware func(next HandleFunc) HandleFunc
+
 // EventDispatcher 事件分发器
 type EventDispatcher struct {
-	queue    MessageQueue
-	handlers map[EventType]EventHandler
-	mu       sync.RWMutex
-	ctx      context.Context
-	cancel   context.CancelFunc
-	wg       sync.WaitGroup
-	closed   bool
+	queue       MessageQueue
+	handlers    map[EventType]EventHandler
+	middlewares []Middleware
+	stopHooks   []func()
+	mu          sync.RWMutex
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	closed      bool
+	sync        bool // 所有分发都在调用方goroutine中同步处理
+	batchSize   int  // worker每次最多取出的事件数，大于1时开启批量处理
+	payloads    PayloadValidator
+
+	delayedMu  sync.Mutex
+	delayed    map[*time.Timer]struct{} // 未配置延迟事件存储时，等待重新入队的进程内延迟事件
+	delayStore DelayStore
+	delayPoll  time.Duration
+
+	receiptsMu sync.Mutex
+	receipts   map[string]*Receipt // 等待处理结果的事件回执，按事件ID索引
 }
 
 // NewEventDispatcher 初始化事件分发器（使用默认channel队列）
@@ -34,6 +74,8 @@ func NewEventDispatcherWithQueue(ctx context.Context, queue MessageQueue) *Event
 		ctx:      ctx,
 		cancel:   cancel,
 		closed:   false,
+		delayed:  make(map[*time.Timer]struct{}),
+		receipts: make(map[string]*Receipt),
 	}
 }
 
@@ -46,11 +88,179 @@ func NewEventDispatcherWithConfig(ctx context.Context, config *QueueConfig) (*Ev
 	return NewEventDispatcherWithQueue(ctx, queue), nil
 }
 
-func (d *EventDispatcher) Dispatch(event Event) {
+// WithSyncMode 开启同步模式，Dispatch等价于DispatchSync，不再经过队列和worker
+// 用于单元测试和对延迟敏感的调用路径，需在分发事件之前调用
+func (d *EventDispatcher) WithSyncMode() *EventDispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sync = true
+	return d
+}
+
+// WithBatchSize 开启批量消费，worker每次最多取出size个事件，
+// 同一类型的事件交给BatchEventHandler一次处理，需在Start之前调用
+func (d *EventDispatcher) WithBatchSize(size int) *EventDispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.batchSize = size
+	return d
+}
+
+// WithPayloadValidator 分发通用事件时按子类型校验payload，未注册的子类型或不合法的payload同步返回*ValidationError
+// 需在分发事件之前调用
+func (d *EventDispatcher) WithPayloadValidator(v PayloadValidator) *EventDispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.payloads = v
+	return d
+}
+
+// Dispatch 校验并推送事件到队列
+// 事件实现了Validator且校验失败时同步返回*ValidationError，不会入队
+func (d *EventDispatcher) Dispatch(event Event) error {
+	if d.isSync() {
+		return d.DispatchSync(event)
+	}
+	if err := d.validate(event); err != nil {
+		return err
+	}
+	return d.push(event)
+}
+
+// DispatchSync 在调用方goroutine中执行中间件、handler及重试，返回处理结果
+// 重试的退避同样阻塞调用方
+func (d *EventDispatcher) DispatchSync(event Event) error {
+	if err := d.validate(event); err != nil {
+		return err
+	}
+	if err := d.checkOpen(); err != nil {
+		return err
+	}
+	if err := d.process(event); !errors.Is(err, ErrDeferred) {
+		return err
+	}
+	return nil
+}
+
+// DispatchWithReceipt 推送事件并返回回执，可通过回执等待事件被处理或丢弃
+// 回执按事件ID关联，事件没有ID时自动生成；同一事件ID在处理结束前只能持有一个回执，重复分发返回ErrDuplicateEventID
+func (d *EventDispatcher) DispatchWithReceipt(event Event) (*Receipt, error) {
+	if err := d.validate(event); err != nil {
+		return nil, err
+	}
+	if e, ok := event.(interface{ setEventID(string) }); ok && event.GetEventID() == "" {
+		e.setEventID(newEventID())
+	}
+	if d.isSync() {
+		if err := d.checkOpen(); err != nil {
+			return nil, err
+		}
+		receipt := newReceipt()
+		receipt.resolve(d.process(event))
+		return receipt, nil
+	}
+
+	id := event.GetEventID()
+	receipt := newReceipt()
+	d.receiptsMu.Lock()
+	if _, exist := d.receipts[id]; exist {
+		d.receiptsMu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateEventID, id)
+	}
+	d.receipts[id] = receipt
+	d.receiptsMu.Unlock()
+
+	if err := d.push(event); err != nil {
+		d.receiptsMu.Lock()
+		delete(d.receipts, id)
+		d.receiptsMu.Unlock()
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// DispatchBatch 校验并批量推送事件，任一事件校验失败时整批拒绝
+func (d *EventDispatcher) DispatchBatch(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	for _, event := range events {
+		if err := d.validate(event); err != nil {
+			return err
+		}
+	}
+	if d.isSync() {
+		var errs []error
+		for _, err := range d.processBatch(events) {
+			if !errors.Is(err, ErrDeferred) {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 	if d.closed {
-		return
+		return ErrDispatcherClosed
+	}
+
+	err := d.queue.PushBatch(d.ctx, events, 5*time.Second)
+	if err != nil {
+		logger.ContextError(d.ctx, "EventDispatcher.DispatchBatch: failed to push events",
+			zap.Int("count", len(events)),
+			zap.Error(err))
+		return fmt.Errorf("push events failed: %w", err)
+	}
+	logger.ContextDebug(d.ctx, "EventDispatcher.DispatchBatch: events dispatched",
+		zap.Int("count", len(events)))
+	return nil
+}
+
+func (d *EventDispatcher) checkOpen() error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+	return nil
+}
+
+func (d *EventDispatcher) isSync() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.sync
+}
+
+func (d *EventDispatcher) validate(event Event) error {
+	var err error
+	if v, ok := event.(Validator); ok {
+		err = v.Validate()
+	}
+	if e, ok := event.(*GenericEvent); ok && err == nil {
+		d.mu.RLock()
+		payloads := d.payloads
+		d.mu.RUnlock()
+		if payloads != nil {
+			if perr := payloads.Validate(e.Subtype, e.Payload); perr != nil {
+				err = &ValidationError{EventType: e.Type, Field: "payload", Reason: perr.Error(), Err: perr}
+			}
+		}
+	}
+	if err != nil {
+		logger.ContextWarn(d.ctx, "EventDispatcher.Dispatch: reject invalid event",
+			zap.String("event_type", string(event.GetType())),
+			zap.Int64("account_id", event.GetAccountID()),
+			zap.Error(err))
+	}
+	return err
+}
+
+func (d *EventDispatcher) push(event Event) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrDispatcherClosed
 	}
 
 	// 使用MessageQueue接口的Push方法
@@ -60,11 +270,105 @@ func (d *EventDispatcher) Dispatch(event Event) {
 			zap.String("event_type", string(event.GetType())),
 			zap.Int64("account_id", event.GetAccountID()),
 			zap.Error(err))
-	} else {
-		logger.ContextDebug(d.ctx, "EventDispatcher.Dispatch: event dispatched",
-			zap.String("event_type", string(event.GetType())),
-			zap.Int64("account_id", event.GetAccountID()))
+		return fmt.Errorf("push event failed: %w", err)
+	}
+	logger.ContextDebug(d.ctx, "EventDispatcher.Dispatch: event dispatched",
+		zap.String("event_type", string(event.GetType())),
+		zap.Int64("account_id", event.GetAccountID()))
+	return nil
+}
+
+// resolveReceipt 结束事件的回执，没有回执时忽略
+func (d *EventDispatcher) resolveReceipt(event Event, err error) {
+	d.receiptsMu.Lock()
+	receipt, exist := d.receipts[event.GetEventID()]
+	delete(d.receipts, event.GetEventID())
+	d.receiptsMu.Unlock()
+	if exist {
+		receipt.resolve(err)
+	}
+}
+
+// DispatchAfter 延迟delay后重新分发事件
+// 配置了延迟事件存储时事件持久化到存储，到期后由任意实例取出重新入队，重启不会丢失；
+// 否则保存在进程内定时器中，Stop时未到期的事件会被丢弃
+func (d *EventDispatcher) DispatchAfter(event Event, delay time.Duration) error {
+	d.mu.RLock()
+	closed := d.closed
+	store := d.delayStore
+	d.mu.RUnlock()
+	if closed {
+		return ErrDispatcherClosed
+	}
+
+	if store != nil {
+		payload, err := serializeEvent(event)
+		if err != nil {
+			return err
+		}
+		if err := store.SaveDelayed(d.ctx, event.GetAccountID(), payload, time.Now().Add(delay)); err != nil {
+			logger.ContextError(d.ctx, "EventDispatcher.DispatchAfter: failed to save delayed event",
+				zap.String("event_type", string(event.GetType())),
+				zap.Int64("account_id", event.GetAccountID()),
+				zap.Error(err))
+			return fmt.Errorf("save delayed event failed: %w", err)
+		}
+		return nil
 	}
+
+	d.delayedMu.Lock()
+	defer d.delayedMu.Unlock()
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		d.delayedMu.Lock()
+		delete(d.delayed, timer)
+		d.delayedMu.Unlock()
+		if err := d.Dispatch(event); err != nil {
+			logger.ContextError(d.ctx, "EventDispatcher.DispatchAfter: failed to dispatch delayed event",
+				zap.String("event_type", string(event.GetType())),
+				zap.Int64("account_id", event.GetAccountID()),
+				zap.Error(err))
+		}
+	})
+	d.delayed[timer] = struct{}{}
+	return nil
+}
+
+// pollDelayed 定时从延迟事件存储中取出到期的事件重新入队
+func (d *EventDispatcher) pollDelayed(store DelayStore, interval time.Duration) {
+	defer d.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for {
+			handled, err := store.ClaimDelayed(d.ctx, time.Now(), delayClaimBatch, d.redispatch)
+			if err != nil && d.ctx.Err() == nil {
+				logger.ContextError(d.ctx, "EventDispatcher.pollDelayed: failed to redispatch delayed events", zap.Error(err))
+			}
+			if err != nil || handled < delayClaimBatch {
+				break
+			}
+		}
+	}
+}
+
+// redispatch 将取出的延迟事件重新入队，无法恢复的事件记录日志后丢弃，入队失败时保留在存储中等待重试
+func (d *EventDispatcher) redispatch(payload []byte) error {
+	event, err := deserializeEvent(d.ctx, payload)
+	if err != nil {
+		logger.ContextError(d.ctx, "EventDispatcher.pollDelayed: drop undecodable delayed event", zap.Error(err))
+		return nil
+	}
+	err = d.Dispatch(event)
+	if errors.Is(err, ErrInvalidEvent) {
+		return nil
+	}
+	return err
 }
 
 func (d *EventDispatcher) RegisterHandler(handler EventHandler) {
@@ -75,16 +379,62 @@ func (d *EventDispatcher) RegisterHandler(handler EventHandler) {
 		zap.String("event_type", string(handler.SupportEventType())))
 }
 
+// Use 注册中间件，按注册顺序由外到内执行，需在Start之前调用
+func (d *EventDispatcher) Use(middlewares ...Middleware) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.middlewares = append(d.middlewares, middlewares...)
+}
+
+// OnStop 注册停止回调，在Stop关闭队列之前执行，用于刷出中间件缓冲的事件
+// 回调中应使用DispatchSync处理事件，此时入队的事件可能因worker随即退出而被丢弃
+func (d *EventDispatcher) OnStop(hook func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopHooks = append(d.stopHooks, hook)
+}
+
+// WithDelayStore 使用持久化存储保存DispatchAfter的延迟事件，Start后每隔interval取出到期的事件重新入队，需在Start之前调用
+func (d *EventDispatcher) WithDelayStore(store DelayStore, interval time.Duration) *EventDispatcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.delayStore = store
+	d.delayPoll = interval
+	return d
+}
+
 // Start 启动
 func (d *EventDispatcher) Start(workerCount int) {
 	for i := 0; i < workerCount; i++ {
 		d.wg.Add(1)
 		go d.worker(i)
 	}
+
+	d.mu.RLock()
+	store, interval := d.delayStore, d.delayPoll
+	d.mu.RUnlock()
+	if store != nil {
+		if interval <= 0 {
+			interval = DefaultDelayPollInterval
+		}
+		d.wg.Add(1)
+		go d.pollDelayed(store, interval)
+	}
 }
 
 // Stop 停止分发
 func (d *EventDispatcher) Stop() {
+	d.mu.RLock()
+	closed := d.closed
+	hooks := d.stopHooks
+	d.mu.RUnlock()
+	if closed {
+		return
+	}
+	for _, hook := range hooks {
+		hook()
+	}
+
 	d.mu.Lock()
 	if d.closed {
 		d.mu.Unlock()
@@ -92,10 +442,31 @@ func (d *EventDispatcher) Stop() {
 	}
 	d.closed = true
 	d.mu.Unlock()
+	d.delayedMu.Lock()
+	dropped := 0
+	for timer := range d.delayed {
+		if timer.Stop() {
+			dropped++
+		}
+	}
+	d.delayed = make(map[*time.Timer]struct{})
+	d.delayedMu.Unlock()
+	if dropped > 0 {
+		logger.ContextWarn(d.ctx, "EventDispatcher.Stop: dropped delayed events", zap.Int("count", dropped))
+	}
+
 	logger.ContextDebug(d.ctx, "EventDispatcher.Stop: waiting for all workers to stop")
 	d.cancel()
 	d.wg.Wait()
 	d.queue.Close()
+
+	// 队列中未处理的事件随停止丢弃
+	d.receiptsMu.Lock()
+	for id, receipt := range d.receipts {
+		receipt.resolve(ErrDispatcherClosed)
+		delete(d.receipts, id)
+	}
+	d.receiptsMu.Unlock()
 	logger.ContextDebug(d.ctx, "EventDispatcher.Stop: all workers stopped")
 }
 
@@ -103,12 +474,29 @@ func (d *EventDispatcher) worker(id int) {
 	defer d.wg.Done()
 	logger.ContextDebug(d.ctx, "EventDispatcher.worker started", zap.Int("id", id))
 
+	d.mu.RLock()
+	batchSize := d.batchSize
+	d.mu.RUnlock()
+
 	for {
 		select {
 		case <-d.ctx.Done():
 			logger.ContextDebug(d.ctx, "EventDispatcher.worker stopped", zap.Int("id", id))
 			return
 		default:
+			if batchSize > 1 {
+				events, err := d.queue.PopBatch(d.ctx, batchSize)
+				if err != nil {
+					if d.ctx.Err() != nil {
+						return
+					}
+					time.Sleep(100 * time.Millisecond)
+					continue
+				}
+				d.handleBatch(events)
+				continue
+			}
+
 			event, err := d.queue.Pop(d.ctx)
 			if err != nil {
 				if d.ctx.Err() != nil {
@@ -123,14 +511,211 @@ func (d *EventDispatcher) worker(id int) {
 }
 
 func (d *EventDispatcher) handleEvent(event Event) {
+	d.resolveReceipt(event, d.process(event))
+}
+
+// process 依次执行中间件和handler，返回最终的处理结果
+func (d *EventDispatcher) process(event Event) error {
+	if d.expired(event) {
+		return ErrEventExpired
+	}
+
+	d.mu.RLock()
+	h := HandleFunc(d.handleWithRetry)
+	for i := len(d.middlewares) - 1; i >= 0; i-- {
+		h = d.middlewares[i](h)
+	}
+	d.mu.RUnlock()
+
+	err := d.safeHandle(h, event)
+	if err != nil && !errors.Is(err, ErrDeferred) {
+		logger.ContextError(d.ctx, "EventDispatcher.handleEvent: event dead-lettered",
+			zap.String("event_type", string(event.GetType())),
+			zap.Int64("account_id", event.GetAccountID()),
+			zap.Error(err))
+	}
+	return err
+}
+
+func (d *EventDispatcher) handleBatch(events []Event) {
+	errs := d.processBatch(events)
+	for i, event := range events {
+		d.resolveReceipt(event, errs[i])
+	}
+}
+
+// processBatch 逐个事件执行中间件，再按事件类型分组交给handler，返回每个事件的处理结果
+// 批量模式下事件在中间件全部执行完后才由handler处理，中间件看到的next返回值总是nil
+func (d *EventDispatcher) processBatch(events []Event) []error {
+	errs := make([]error, len(events))
+
+	// 经过中间件后的事件及其对应的原始事件下标，中间件可能改写事件类型
+	var (
+		passed  []Event
+		origins []int
+	)
+	d.mu.RLock()
+	middlewares := d.middlewares
+	d.mu.RUnlock()
+	for i, event := range events {
+		if d.expired(event) {
+			errs[i] = ErrEventExpired
+			continue
+		}
+		collect := HandleFunc(func(e Event) error {
+			passed = append(passed, e)
+			origins = append(origins, i)
+			return nil
+		})
+		h := collect
+		for j := len(middlewares) - 1; j >= 0; j-- {
+			h = middlewares[j](h)
+		}
+		errs[i] = d.safeHandle(h, event)
+	}
+
+	var types []EventType
+	groups := make(map[EventType][]int)
+	for i, e := range passed {
+		if _, exist := groups[e.GetType()]; !exist {
+			types = append(types, e.GetType())
+		}
+		groups[e.GetType()] = append(groups[e.GetType()], i)
+	}
+
+	d.mu.RLock()
+	batchSize := d.batchSize
+	d.mu.RUnlock()
+	for _, eventType := range types {
+		indexes := groups[eventType]
+		d.mu.RLock()
+		handler := d.handlers[eventType]
+		d.mu.RUnlock()
+
+		batchHandler, ok := handler.(BatchEventHandler)
+		if !ok || len(indexes) == 1 {
+			for _, i := range indexes {
+				if err := d.safeHandle(d.handleWithRetry, passed[i]); err != nil && errs[origins[i]] == nil {
+					errs[origins[i]] = err
+				}
+			}
+			continue
+		}
+
+		// 同步模式下未设置批量大小时整组一次处理
+		chunkSize := batchSize
+		if chunkSize <= 1 {
+			chunkSize = len(indexes)
+		}
+		for start := 0; start < len(indexes); start += chunkSize {
+			end := min(start+chunkSize, len(indexes))
+			chunk := make([]Event, 0, end-start)
+			for _, i := range indexes[start:end] {
+				chunk = append(chunk, passed[i])
+			}
+			err := d.safeHandle(func(Event) error {
+				return d.handleBatchWithRetry(batchHandler, chunk)
+			}, chunk[0])
+			if err == nil {
+				continue
+			}
+			for _, i := range indexes[start:end] {
+				if errs[origins[i]] == nil {
+					errs[origins[i]] = err
+				}
+			}
+		}
+	}
+
+	for i, err := range errs {
+		if err != nil && err != ErrEventExpired && !errors.Is(err, ErrDeferred) {
+			logger.ContextError(d.ctx, "EventDispatcher.handleEvent: event dead-lettered",
+				zap.String("event_type", string(events[i].GetType())),
+				zap.Int64("account_id", events[i].GetAccountID()),
+				zap.Error(err))
+		}
+	}
+	return errs
+}
+
+// expired 检查事件是否已过期，过期事件直接丢弃
+func (d *EventDispatcher) expired(event Event) bool {
+	e, ok := event.(Expirable)
+	if !ok || !e.Expired(time.Now()) {
+		return false
+	}
+	logger.ContextWarn(d.ctx, "EventDispatcher.handleEvent: drop expired event",
+		zap.String("event_type", string(event.GetType())),
+		zap.Int64("account_id", event.GetAccountID()),
+		zap.Time("expires_at", *e.GetExpiresAt()))
+	return true
+}
+
+// safeHandle 执行h并将panic转换为错误
+func (d *EventDispatcher) safeHandle(h HandleFunc, event Event) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			logger.ContextError(d.ctx, "EventDispatcher.handleEvent: panic",
 				zap.String("event_type", string(event.GetType())),
 				zap.Any("error", r))
+			err = fmt.Errorf("handle event panic: %v", r)
 		}
 	}()
+	return h(event)
+}
 
+// handleBatchWithRetry 批量处理同一类型的事件并按退避重试，重试耗尽或返回ErrInvalidEvent时返回该错误
+func (d *EventDispatcher) handleBatchWithRetry(handler BatchEventHandler, events []Event) error {
+	eventType := string(handler.SupportEventType())
+	maxRetries := 3
+	var lastErr error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		start := time.Now()
+		err := handler.HandleBatch(events)
+		duration := time.Since(start)
+		if err == nil {
+			logger.ContextDebug(d.ctx, "EventDispatcher.handleBatch: handle events success",
+				zap.String("event_type", eventType),
+				zap.Int("count", len(events)),
+				zap.Int("attempt", attempt),
+				zap.Duration("duration", duration))
+			return nil
+		}
+		lastErr = err
+		if errors.Is(err, ErrInvalidEvent) {
+			logger.ContextError(d.ctx, "EventDispatcher.handleBatch: invalid events, skip retry",
+				zap.String("event_type", eventType),
+				zap.Int("count", len(events)),
+				zap.Int("attempt", attempt),
+				zap.Error(err))
+			return err
+		}
+		if attempt < maxRetries {
+			backoff := time.Duration(attempt) * time.Second
+			logger.ContextWarn(d.ctx, "EventDispatcher.handleBatch: handle events failed, will retry",
+				zap.String("event_type", eventType),
+				zap.Int("count", len(events)),
+				zap.Int("attempt", attempt),
+				zap.Int("max_retries", maxRetries),
+				zap.Duration("backoff", backoff),
+				zap.Error(err))
+			time.Sleep(backoff)
+		} else {
+			logger.ContextError(d.ctx, "EventDispatcher.handleBatch: handle events failed after all retries - MESSAGES LOST",
+				zap.String("event_type", eventType),
+				zap.Int("count", len(events)),
+				zap.Int("total_attempts", maxRetries),
+				zap.Duration("total_duration", duration),
+				zap.Error(lastErr),
+				zap.Stack("stack_trace"))
+		}
+	}
+	return lastErr
+}
+
+// handleWithRetry 路由到handler并按退避重试，重试耗尽时返回最后一次错误，失败已在内部记录日志
+// handler返回ErrInvalidEvent时不重试
+func (d *EventDispatcher) handleWithRetry(event Event) error {
 	d.mu.RLock()
 	handler, exist := d.handlers[event.GetType()]
 	d.mu.RUnlock()
@@ -138,7 +723,7 @@ func (d *EventDispatcher) handleEvent(event Event) {
 		logger.ContextError(d.ctx, "EventDispatcher.handleEvent: no handler for event",
 			zap.String("event_type", string(event.GetType())),
 			zap.Int64("account_id", event.GetAccountID()))
-		return
+		return ErrNoHandler
 	}
 
 	maxRetries := 3
@@ -154,9 +739,18 @@ func (d *EventDispatcher) handleEvent(event Event) {
 				zap.Int64("account_id", event.GetAccountID()),
 				zap.Int("attempt", attempt),
 				zap.Duration("duration", duration))
-			return
+			return nil
 		}
 		lastErr = err
+		if errors.Is(err, ErrInvalidEvent) {
+			// 事件本身不合法，重试无意义
+			logger.ContextError(d.ctx, "EventDispatcher.handleEvent: invalid event, skip retry",
+				zap.String("event_type", string(event.GetType())),
+				zap.Int64("account_id", event.GetAccountID()),
+				zap.Int("attempt", attempt),
+				zap.Error(err))
+			return err
+		}
 		if attempt < maxRetries {
 			backoff := time.Duration(attempt) * time.Second
 			logger.ContextWarn(d.ctx, "EventDispatcher.handleEvent: handle event failed, will retry",
@@ -177,6 +771,7 @@ func (d *EventDispatcher) handleEvent(event Event) {
 				zap.Stack("stack_trace"))
 		}
 	}
+	return lastErr
 }
 
 func (d *EventDispatcher) GetEventChannelLen() int {
@@ -186,3 +781,10 @@ func (d *EventDispatcher) GetEventChannelLen() int {
 func (d *EventDispatcher) GetEventChanCap() int {
 	return d.queue.Cap()
 }
+
+// GetDelayedLen 获取等待重新入队的延迟事件数
+func (d *EventDispatcher) GetDelayedLen() int {
+	d.delayedMu.Lock()
+	defer d.delayedMu.Unlock()
+	return len(d.delayed)
+}
@@ -2,7 +2,11 @@ package notification
 
 import (
 	"context"
+	"crypto/rand"
 	"time"
+
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/constants"
 )
 
 type EventType string
@@ -12,23 +16,67 @@ type EventHandler interface {
 	SupportEventType() EventType
 }
 
+// BatchEventHandler 支持批量处理的handler，分发器开启批量消费时一次传入同一类型的多个事件
+// 返回错误时整批按失败重试，实现需保证批量处理的原子性
+type BatchEventHandler interface {
+	EventHandler
+	HandleBatch(events []Event) error
+}
+
 const (
-	EventTypeManuscript EventType = "manuscript"
-	EventTypeAward      EventType = "award"
+	EventTypeManuscript    EventType = "manuscript"
+	EventTypeAward         EventType = "award"
+	EventTypeCertification EventType = "certification"
+	EventTypeDelivery      EventType = "delivery"
+	EventTypeDigest        EventType = "digest"
+	EventTypeGeneric       EventType = "generic"
 )
 
 type Event interface {
+	GetEventID() string
 	GetType() EventType
 	GetAccountID() int64
 	GetContext() context.Context
 	GetTimeStamp() time.Time
 }
 
+// Routable 可按通知类型和投递渠道路由的事件，用于偏好过滤等
+type Routable interface {
+	GetNotifyType() int8
+	GetChannel() channel.Channel
+}
+
+// Expirable 有过期时间的事件，过期后不再处理
+type Expirable interface {
+	GetExpiresAt() *time.Time
+	Expired(now time.Time) bool
+}
+
+// ExpiresAt 返回事件的过期时间，事件不可过期或不过期时返回nil
+func ExpiresAt(event Event) *time.Time {
+	if e, ok := event.(Expirable); ok {
+		return e.GetExpiresAt()
+	}
+	return nil
+}
+
+// Collapsible 可折叠的事件，同一账号下折叠键相同的未读通知会被新事件覆盖而不是新增
+type Collapsible interface {
+	GetCollapseKey() string
+}
+
 type BaseEvent struct {
-	Type    EventType       `json:"type"`
-	Account int64           `json:"account"`
-	Ctx     context.Context `json:"ctx"`
-	Time    time.Time       `json:"time"`
+	ID         string          `json:"id"` // 事件ID，经过队列序列化后保持不变，用于关联回执
+	Type       EventType       `json:"type"`
+	Account    int64           `json:"account"`
+	Ctx        context.Context `json:"-"`
+	Time       time.Time       `json:"time"`
+	Aggregated bool            `json:"aggregated"`           // 已经过聚合阶段，不再重复聚合
+	ExpiresAt  *time.Time      `json:"expires_at,omitempty"` // 过期时间，过期后仍在队列中的事件会被丢弃，为空表示不过期
+}
+
+func (e BaseEvent) GetEventID() string {
+	return e.ID
 }
 
 func (e BaseEvent) GetType() EventType {
@@ -47,6 +95,32 @@ func (e BaseEvent) GetTimeStamp() time.Time {
 	return e.Time
 }
 
+// GetExpiresAt 过期时间，为nil表示不过期
+func (e BaseEvent) GetExpiresAt() *time.Time {
+	return e.ExpiresAt
+}
+
+// Expired 事件在now时是否已过期
+func (e BaseEvent) Expired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+func (e *BaseEvent) setEventID(id string) {
+	e.ID = id
+}
+
+func (e *BaseEvent) setContext(ctx context.Context) {
+	e.Ctx = ctx
+}
+
+func (e BaseEvent) isAggregated() bool {
+	return e.Aggregated
+}
+
+func (e *BaseEvent) markAggregated() {
+	e.Aggregated = true
+}
+
 type ManuscriptEvent struct {
 	BaseEvent
 	ManuscriptId string `json:"manuscript_id"`
@@ -65,9 +139,90 @@ type AwardEvent struct {
 	ActivityName string `json:"activity_name"`
 }
 
+type CertificationEvent struct {
+	BaseEvent
+	CertificationId   string `json:"certification_id"`
+	CertificationType string `json:"certification_type"`
+	Status            int8   `json:"status"`
+	AuditReason       string `json:"audit_reason"`
+	OperateUser       string `json:"operate_user"`
+	ActivityName      string `json:"activity_name"`
+}
+
+// GenericEvent 通用事件，payload按子类型注册的JSON Schema校验，由通用handler按模板渲染
+type GenericEvent struct {
+	BaseEvent
+	Subtype string         `json:"subtype"`
+	Payload map[string]any `json:"payload"`
+}
+
+// DeliveryEvent 外部渠道投递事件（短信等），内容由业务handler渲染后投递
+type DeliveryEvent struct {
+	BaseEvent
+	Channel    channel.Channel `json:"channel"`
+	NotifyType int8            `json:"notify_type"`
+	Title      string          `json:"title"`
+	Content    string          `json:"content"`
+}
+
+func (e *ManuscriptEvent) GetNotifyType() int8 {
+	return constants.NOTIFICATION_TYPE_MANUSCRIPT_AUDIT
+}
+
+func (e *ManuscriptEvent) GetChannel() channel.Channel {
+	return channel.ChannelInbox
+}
+
+// GetCollapseKey 同一稿件的审核状态通知相互覆盖
+func (e *ManuscriptEvent) GetCollapseKey() string {
+	return "manuscript:" + e.ManuscriptId
+}
+
+func (e *AwardEvent) GetNotifyType() int8 {
+	return constants.NOTIFICATION_TYPE_REWARD_DISTRIBUTE
+}
+
+func (e *AwardEvent) GetChannel() channel.Channel {
+	return channel.ChannelInbox
+}
+
+func (e *CertificationEvent) GetNotifyType() int8 {
+	return constants.NOTIFICATION_TYPE_CERTIFICATION_AUDIT
+}
+
+func (e *CertificationEvent) GetChannel() channel.Channel {
+	return channel.ChannelInbox
+}
+
+func (e *DeliveryEvent) GetNotifyType() int8 {
+	return e.NotifyType
+}
+
+func (e *DeliveryEvent) GetChannel() channel.Channel {
+	return e.Channel
+}
+
+// DigestEvent 汇总事件，由聚合阶段将同一账号同一活动的多条事件合并而成
+type DigestEvent struct {
+	BaseEvent
+	Key          string             `json:"key"`
+	ActivityName string             `json:"activity_name"`
+	Manuscripts  []*ManuscriptEvent `json:"manuscripts"`
+	Awards       []*AwardEvent      `json:"awards"`
+}
+
+func (e *DigestEvent) GetNotifyType() int8 {
+	return constants.NOTIFICATION_TYPE_DIGEST
+}
+
+func (e *DigestEvent) GetChannel() channel.Channel {
+	return channel.ChannelInbox
+}
+
 func NewManuscriptAuditEvent(ctx context.Context, accountId int64, manuscriptId string, oldStatus int8, newStatus int8) *ManuscriptEvent {
 	return &ManuscriptEvent{
 		BaseEvent: BaseEvent{
+			ID:      newEventID(),
 			Type:    EventTypeManuscript,
 			Account: accountId,
 			Ctx:     ctx,
@@ -82,6 +237,7 @@ func NewManuscriptAuditEvent(ctx context.Context, accountId int64, manuscriptId
 func NewAwardEvent(ctx context.Context, accountId int64, manuscriptId string, awardAmount int, awardType string) *AwardEvent {
 	return &AwardEvent{
 		BaseEvent: BaseEvent{
+			ID:      newEventID(),
 			Type:    EventTypeAward,
 			Account: accountId,
 			Ctx:     ctx,
@@ -92,3 +248,53 @@ func NewAwardEvent(ctx context.Context, accountId int64, manuscriptId string, aw
 		AwardAmount:  awardAmount,
 	}
 }
+
+func NewCertificationAuditEvent(ctx context.Context, accountId int64, certificationId string, certificationType string, status int8) *CertificationEvent {
+	return &CertificationEvent{
+		BaseEvent: BaseEvent{
+			ID:      newEventID(),
+			Type:    EventTypeCertification,
+			Account: accountId,
+			Ctx:     ctx,
+			Time:    time.Now(),
+		},
+		CertificationId:   certificationId,
+		CertificationType: certificationType,
+		Status:            status,
+	}
+}
+
+func NewGenericEvent(ctx context.Context, accountId int64, subtype string, payload map[string]any) *GenericEvent {
+	return &GenericEvent{
+		BaseEvent: BaseEvent{
+			ID:      newEventID(),
+			Type:    EventTypeGeneric,
+			Account: accountId,
+			Ctx:     ctx,
+			Time:    time.Now(),
+		},
+		Subtype: subtype,
+		Payload: payload,
+	}
+}
+
+func NewDeliveryEvent(ctx context.Context, accountId int64, ch channel.Channel, notifyType int8, title, content string) *DeliveryEvent {
+	return &DeliveryEvent{
+		BaseEvent: BaseEvent{
+			ID:      newEventID(),
+			Type:    EventTypeDelivery,
+			Account: accountId,
+			Ctx:     ctx,
+			Time:    time.Now(),
+		},
+		Channel:    ch,
+		NotifyType: notifyType,
+		Title:      title,
+		Content:    content,
+	}
+}
+
+// newEventID 生成随机的事件ID
+func newEventID() string {
+	return rand.Text()
+}
@@ -2,9 +2,12 @@ package notification
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/channel"
 )
 
 var (
@@ -12,6 +15,9 @@ var (
 	once          sync.Once
 )
 
+// ErrManagerNotInitialized 全局通知管理器未初始化
+var ErrManagerNotInitialized = errors.New("notification manager is not initialized")
+
 type Manager struct {
 	dispatcher *EventDispatcher
 	ctx        context.Context
@@ -33,10 +39,27 @@ func GetGlobalManager() *Manager {
 	return globalManager
 }
 
-func (m *Manager) Dispatcher(event Event) {
-	if m.dispatcher != nil {
-		m.dispatcher.Dispatch(event)
+func (m *Manager) Dispatcher(event Event) error {
+	if m.dispatcher == nil {
+		return ErrDispatcherClosed
 	}
+	return m.dispatcher.Dispatch(event)
+}
+
+// DispatchBatch 批量分发事件
+func (m *Manager) DispatchBatch(events []Event) error {
+	if m.dispatcher == nil {
+		return ErrDispatcherClosed
+	}
+	return m.dispatcher.DispatchBatch(events)
+}
+
+// DispatchWithReceipt 分发事件并返回回执
+func (m *Manager) DispatchWithReceipt(event Event) (*Receipt, error) {
+	if m.dispatcher == nil {
+		return nil, ErrDispatcherClosed
+	}
+	return m.dispatcher.DispatchWithReceipt(event)
 }
 
 func (m *Manager) Stop() {
@@ -52,29 +75,82 @@ func (m *Manager) GetMetrics() map[string]interface{} {
 	return map[string]interface{}{
 		"event_channel_len": m.dispatcher.GetEventChannelLen(),
 		"event_channel_cap": m.dispatcher.GetEventChanCap(),
+		"delayed_events":    m.dispatcher.GetDelayedLen(),
+	}
+}
+
+// DispatchWithReceipt 通过全局管理器分发事件并返回回执，供管理工具等需要等待处理结果的场景使用
+func DispatchWithReceipt(ctx context.Context, event Event) (*Receipt, error) {
+	if globalManager == nil {
+		logger.ContextWarn(ctx, "DispatchWithReceipt: global manager is not initialized")
+		return nil, ErrManagerNotInitialized
+	}
+	return globalManager.DispatchWithReceipt(event)
+}
+
+// DispatchBatch 通过全局管理器批量分发事件，用于给活动所有获奖者发放奖励等批量场景
+func DispatchBatch(ctx context.Context, events []Event) error {
+	if globalManager == nil {
+		logger.ContextWarn(ctx, "DispatchBatch: global manager is not initialized")
+		return ErrManagerNotInitialized
 	}
+	return globalManager.DispatchBatch(events)
 }
 
 // DispatchManuscriptAuditEvent 分发稿件审核事件
-func DispatchManuscriptAuditEvent(ctx context.Context, accountID int64, manuscriptID string, oldStatus, newStatus int8, auditReason, operateUser, activityName string) {
+func DispatchManuscriptAuditEvent(ctx context.Context, accountID int64, manuscriptID string, oldStatus, newStatus int8, auditReason, operateUser, activityName string) error {
 	if globalManager == nil {
 		logger.ContextWarn(ctx, "DispatchManuscriptAuditEvent: global manager is not initialized")
-		return
+		return ErrManagerNotInitialized
 	}
 	event := NewManuscriptAuditEvent(ctx, accountID, manuscriptID, oldStatus, newStatus)
 	event.AuditReason = auditReason
 	event.OperateUser = operateUser
 	event.ActivityName = activityName
-	globalManager.Dispatcher(event)
+	return globalManager.Dispatcher(event)
 }
 
 // DispatchAwardEvent 分发奖励发放事件
-func DispatchAwardEvent(ctx context.Context, accountID int64, manuscriptID string, awardAmount int, awardType, activityName string) {
+func DispatchAwardEvent(ctx context.Context, accountID int64, manuscriptID string, awardAmount int, awardType, activityName string) error {
 	if globalManager == nil {
 		logger.ContextWarn(ctx, "DispatchAwardEvent: global manager is not initialized")
-		return
+		return ErrManagerNotInitialized
 	}
 	event := NewAwardEvent(ctx, accountID, manuscriptID, awardAmount, awardType)
 	event.ActivityName = activityName
-	globalManager.Dispatcher(event)
+	return globalManager.Dispatcher(event)
+}
+
+// DispatchCertificationAuditEvent 分发认证审核事件
+func DispatchCertificationAuditEvent(ctx context.Context, accountID int64, certificationID, certificationType string, status int8, auditReason, operateUser, activityName string) error {
+	if globalManager == nil {
+		logger.ContextWarn(ctx, "DispatchCertificationAuditEvent: global manager is not initialized")
+		return ErrManagerNotInitialized
+	}
+	event := NewCertificationAuditEvent(ctx, accountID, certificationID, certificationType, status)
+	event.AuditReason = auditReason
+	event.OperateUser = operateUser
+	event.ActivityName = activityName
+	return globalManager.Dispatcher(event)
+}
+
+// DispatchGenericEvent 分发通用事件，subtype需已在通用通知注册表中定义
+// 分发器配置了WithPayloadValidator时，未注册的子类型或不合法的payload同步返回ErrInvalidEvent
+func DispatchGenericEvent(ctx context.Context, accountID int64, subtype string, payload map[string]any) error {
+	if globalManager == nil {
+		logger.ContextWarn(ctx, "DispatchGenericEvent: global manager is not initialized")
+		return ErrManagerNotInitialized
+	}
+	return globalManager.Dispatcher(NewGenericEvent(ctx, accountID, subtype, payload))
+}
+
+// DispatchDeliveryEvent 分发外部渠道投递事件，expiresAt为nil表示不过期
+func DispatchDeliveryEvent(ctx context.Context, accountID int64, ch channel.Channel, notifyType int8, title, content string, expiresAt *time.Time) error {
+	if globalManager == nil {
+		logger.ContextWarn(ctx, "DispatchDeliveryEvent: global manager is not initialized")
+		return ErrManagerNotInitialized
+	}
+	event := NewDeliveryEvent(ctx, accountID, ch, notifyType, title, content)
+	event.ExpiresAt = expiresAt
+	return globalManager.Dispatcher(event)
 }
@@ -13,6 +13,12 @@ type MessageQueue interface {
 	// Pop 从队列获取事件，阻塞直到有事件或context取消
 	Pop(ctx context.Context) (Event, error)
 
+	// PushBatch 批量推送事件，超时返回错误时可能已推送部分事件
+	PushBatch(ctx context.Context, events []Event, timeout time.Duration) error
+
+	// PopBatch 批量获取事件，阻塞直到至少有一个事件，最多返回max个
+	PopBatch(ctx context.Context, max int) ([]Event, error)
+
 	// Close 关闭队列
 	Close() error
 
@@ -3,6 +3,7 @@ package notification
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -49,6 +50,47 @@ func (q *ChannelQueue) Pop(ctx context.Context) (Event, error) {
 	}
 }
 
+// PushBatch 批量推送事件到队列，所有事件共用一个超时时间
+func (q *ChannelQueue) PushBatch(ctx context.Context, events []Event, timeout time.Duration) error {
+	if q.closed {
+		return errors.New("queue is closed")
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for i, event := range events {
+		select {
+		case q.eventChan <- event:
+		case <-timer.C:
+			return fmt.Errorf("push timeout: queue is full, %d of %d events pushed", i, len(events))
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// PopBatch 从队列批量获取事件，取到第一个事件后不再等待
+func (q *ChannelQueue) PopBatch(ctx context.Context, max int) ([]Event, error) {
+	event, err := q.Pop(ctx)
+	if err != nil {
+		return nil, err
+	}
+	events := []Event{event}
+	for len(events) < max {
+		select {
+		case event, ok := <-q.eventChan:
+			if !ok {
+				return events, nil
+			}
+			events = append(events, event)
+		default:
+			return events, nil
+		}
+	}
+	return events, nil
+}
+
 // Close 关闭队列
 func (q *ChannelQueue) Close() error {
 	if q.closed {
@@ -124,6 +124,52 @@ func (q *KafkaQueue) Pop(ctx context.Context) (Event, error) {
 	}
 }
 
+// PushBatch 批量推送事件到Kafka
+func (q *KafkaQueue) PushBatch(ctx context.Context, events []Event, timeout time.Duration) error {
+	// TODO: 实现Kafka生产者批量发送消息
+	/*
+		messages := make([]*sarama.ProducerMessage, 0, len(events))
+		for _, event := range events {
+			data, err := serializeEvent(event)
+			if err != nil {
+				return fmt.Errorf("serialize event failed: %w", err)
+			}
+			messages = append(messages, &sarama.ProducerMessage{
+				Topic: q.topic,
+				Key:   sarama.StringEncoder(fmt.Sprintf("%d", event.GetAccountID())),
+				Value: sarama.ByteEncoder(data),
+			})
+		}
+
+		if err := q.producer.SendMessages(messages); err != nil {
+			return fmt.Errorf("kafka send failed: %w", err)
+		}
+		return nil
+	*/
+	return errors.New("not implemented")
+}
+
+// PopBatch 从Kafka批量消费消息，取到第一条后不再等待
+func (q *KafkaQueue) PopBatch(ctx context.Context, max int) ([]Event, error) {
+	event, err := q.Pop(ctx)
+	if err != nil {
+		return nil, err
+	}
+	events := []Event{event}
+	for len(events) < max {
+		select {
+		case event, ok := <-q.msgChan:
+			if !ok {
+				return events, nil
+			}
+			events = append(events, event)
+		default:
+			return events, nil
+		}
+	}
+	return events, nil
+}
+
 // consumeMessages 消费Kafka消息的后台goroutine
 //
 //nolint:unused // 预留方法，待实现时使用
@@ -188,7 +234,7 @@ func (h *kafkaConsumerHandler) Cleanup(sarama.ConsumerGroupSession) error {
 func (h *kafkaConsumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for message := range claim.Messages() {
 		// 反序列化事件
-		event, err := deserializeEvent(message.Value)
+		event, err := deserializeEvent(session.Context(), message.Value)
 		if err != nil {
 			// 记录错误，继续处理下一条
 			continue
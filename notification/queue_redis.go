@@ -99,7 +99,7 @@ func (q *RedisQueue) Pop(ctx context.Context) (Event, error) {
 		}
 
 		// 反序列化事件
-		event, err := deserializeEvent([]byte(result[1]))
+		event, err := deserializeEvent(ctx, []byte(result[1]))
 		if err != nil {
 			return nil, fmt.Errorf("deserialize event failed: %w", err)
 		}
@@ -109,6 +109,56 @@ func (q *RedisQueue) Pop(ctx context.Context) (Event, error) {
 	return nil, errors.New("not implemented")
 }
 
+// PushBatch 批量推送事件到Redis队列
+func (q *RedisQueue) PushBatch(ctx context.Context, events []Event, timeout time.Duration) error {
+	// TODO: 实现Redis多值LPUSH操作
+	/*
+		values := make([]interface{}, 0, len(events))
+		for _, event := range events {
+			data, err := serializeEvent(event)
+			if err != nil {
+				return fmt.Errorf("serialize event failed: %w", err)
+			}
+			values = append(values, data)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		if err := q.redisClient.LPush(ctx, q.queueKey, values...).Err(); err != nil {
+			return fmt.Errorf("redis lpush failed: %w", err)
+		}
+		return nil
+	*/
+	return errors.New("not implemented")
+}
+
+// PopBatch 从Redis队列批量获取事件
+func (q *RedisQueue) PopBatch(ctx context.Context, max int) ([]Event, error) {
+	// TODO: 先BRPOP阻塞获取一条，再用RPOP count取出剩余
+	/*
+		first, err := q.Pop(ctx)
+		if err != nil {
+			return nil, err
+		}
+		events := []Event{first}
+
+		values, err := q.redisClient.RPopCount(ctx, q.queueKey, max-1).Result()
+		if err != nil && err != redis.Nil {
+			return events, nil
+		}
+		for _, value := range values {
+			event, err := deserializeEvent(ctx, []byte(value))
+			if err != nil {
+				continue
+			}
+			events = append(events, event)
+		}
+		return events, nil
+	*/
+	return nil, errors.New("not implemented")
+}
+
 // Close 关闭Redis连接
 func (q *RedisQueue) Close() error {
 	// TODO: 关闭Redis连接
@@ -137,9 +187,8 @@ func (q *RedisQueue) Cap() int {
 	return q.bufferSize
 }
 
-// serializeEvent 序列化事件
-//
-//nolint:unused // 预留函数，待实现时使用
+// serializeEvent 序列化事件，用于跨进程的队列和持久化的延迟事件
+// 事件的context不会被序列化
 func serializeEvent(event Event) ([]byte, error) {
 	// 根据事件类型进行序列化
 	switch e := event.(type) {
@@ -147,36 +196,50 @@ func serializeEvent(event Event) ([]byte, error) {
 		return json.Marshal(e)
 	case *AwardEvent:
 		return json.Marshal(e)
+	case *CertificationEvent:
+		return json.Marshal(e)
+	case *GenericEvent:
+		return json.Marshal(e)
+	case *DeliveryEvent:
+		return json.Marshal(e)
+	case *DigestEvent:
+		return json.Marshal(e)
 	default:
 		return nil, fmt.Errorf("unknown event type: %T", event)
 	}
 }
 
-// deserializeEvent 反序列化事件
-//
-//nolint:unused // 预留函数，待实现时使用
-func deserializeEvent(data []byte) (Event, error) {
-	// 需要先解析出事件类型
-	// 这里简化处理，实际需要更复杂的类型判断
+// deserializeEvent 反序列化事件，事件的context设置为ctx
+func deserializeEvent(ctx context.Context, data []byte) (Event, error) {
+	// 先解析出事件类型
 	var base BaseEvent
 	if err := json.Unmarshal(data, &base); err != nil {
 		return nil, err
 	}
 
+	var event interface {
+		Event
+		setContext(ctx context.Context)
+	}
 	switch base.Type {
 	case EventTypeManuscript:
-		var event ManuscriptEvent
-		if err := json.Unmarshal(data, &event); err != nil {
-			return nil, err
-		}
-		return &event, nil
+		event = &ManuscriptEvent{}
 	case EventTypeAward:
-		var event AwardEvent
-		if err := json.Unmarshal(data, &event); err != nil {
-			return nil, err
-		}
-		return &event, nil
+		event = &AwardEvent{}
+	case EventTypeCertification:
+		event = &CertificationEvent{}
+	case EventTypeGeneric:
+		event = &GenericEvent{}
+	case EventTypeDelivery:
+		event = &DeliveryEvent{}
+	case EventTypeDigest:
+		event = &DigestEvent{}
 	default:
 		return nil, fmt.Errorf("unknown event type: %s", base.Type)
 	}
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil, err
+	}
+	event.setContext(ctx)
+	return event, nil
 }
@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNoHandler 事件类型没有注册handler
+var ErrNoHandler = errors.New("no handler for event")
+
+// ErrEventExpired 事件在处理前已过期，被丢弃
+var ErrEventExpired = errors.New("event expired before handling")
+
+// ErrDuplicateEventID 同一事件ID已有未结束的回执
+var ErrDuplicateEventID = errors.New("event id already has a pending receipt")
+
+// ErrDeferred 中间件延迟或聚合了事件，事件稍后以重新分发或汇总事件的形式继续处理
+// 中间件返回该错误时事件不计为失败，回执状态为ReceiptDeferred
+var ErrDeferred = errors.New("event deferred")
+
+// ReceiptStatus 事件处理结果
+type ReceiptStatus int
+
+const (
+	ReceiptPending      ReceiptStatus = iota // 等待处理
+	ReceiptHandled                           // 处理完成（包括被中间件跳过）
+	ReceiptDeadLettered                      // 重试耗尽或无法处理，事件已丢弃
+	ReceiptDeferred                          // 被中间件延迟或聚合，稍后重新分发的事件不再关联该回执
+)
+
+func (s ReceiptStatus) String() string {
+	switch s {
+	case ReceiptHandled:
+		return "handled"
+	case ReceiptDeadLettered:
+		return "dead_lettered"
+	case ReceiptDeferred:
+		return "deferred"
+	default:
+		return "pending"
+	}
+}
+
+// Receipt 事件投递回执，可等待事件被处理或丢弃
+// 回执按事件ID保存在进程内，只对同一进程中的worker处理的事件生效
+type Receipt struct {
+	done   chan struct{}
+	once   sync.Once
+	status ReceiptStatus
+	err    error
+}
+
+func newReceipt() *Receipt {
+	return &Receipt{done: make(chan struct{})}
+}
+
+func (r *Receipt) resolve(err error) {
+	r.once.Do(func() {
+		r.status = ReceiptHandled
+		if errors.Is(err, ErrDeferred) {
+			r.status = ReceiptDeferred
+		} else if err != nil {
+			r.status = ReceiptDeadLettered
+			r.err = err
+		}
+		close(r.done)
+	})
+}
+
+// Done 事件处理结束时关闭
+func (r *Receipt) Done() <-chan struct{} {
+	return r.done
+}
+
+// Status 当前处理结果
+func (r *Receipt) Status() ReceiptStatus {
+	select {
+	case <-r.done:
+		return r.status
+	default:
+		return ReceiptPending
+	}
+}
+
+// Wait 等待事件处理结束，返回处理失败的原因，事件被延迟或聚合时返回nil；ctx取消时返回ctx.Err()
+func (r *Receipt) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,155 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereal3x/notice/notification"
+)
+
+// copyingQueue 入队时序列化事件，出队得到新的事件实例，模拟Redis、Kafka等外部队列
+type copyingQueue struct {
+	notification.MessageQueue
+}
+
+func (q *copyingQueue) Push(ctx context.Context, event notification.Event, timeout time.Duration) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	copied := &notification.AwardEvent{}
+	if err := json.Unmarshal(data, copied); err != nil {
+		return err
+	}
+	copied.Ctx = event.GetContext()
+	return q.MessageQueue.Push(ctx, copied, timeout)
+}
+
+// awardRecorder 记录收到的奖励事件
+type awardRecorder struct {
+	events []*notification.AwardEvent
+}
+
+func (h *awardRecorder) Handle(event notification.Event) error {
+	h.events = append(h.events, event.(*notification.AwardEvent))
+	return nil
+}
+
+func (h *awardRecorder) SupportEventType() notification.EventType {
+	return notification.EventTypeAward
+}
+
+func TestReceiptResolvedAfterQueueCopy(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := notification.NewEventDispatcherWithQueue(ctx, &copyingQueue{notification.NewChannelQueue(10)})
+	recorder := &awardRecorder{}
+	dispatcher.RegisterHandler(recorder)
+	dispatcher.Start(1)
+	defer dispatcher.Stop()
+
+	receipt, err := dispatcher.DispatchWithReceipt(notification.NewAwardEvent(ctx, 1, "MS001", 100, "cash"))
+	if err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := receipt.Wait(waitCtx); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if receipt.Status() != notification.ReceiptHandled || len(recorder.events) != 1 {
+		t.Fatalf("receipt = %s, handled %d events", receipt.Status(), len(recorder.events))
+	}
+}
+
+func TestReceiptDeferredWhenAggregated(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := notification.NewEventDispatcher(ctx, 10)
+	aggregator := notification.NewAggregator(dispatcher, notification.NewActivityDigestRule(time.Hour))
+	dispatcher.Use(aggregator.Middleware())
+	dispatcher.RegisterHandler(&digestRecorder{})
+	dispatcher.Start(1)
+	defer dispatcher.Stop()
+
+	event := notification.NewAwardEvent(ctx, 1, "MS001", 100, "cash")
+	event.ActivityName = "spring"
+	receipt, err := dispatcher.DispatchWithReceipt(event)
+	if err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if err := receipt.Wait(ctx); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if receipt.Status() != notification.ReceiptDeferred {
+		t.Fatalf("receipt = %s, want deferred", receipt.Status())
+	}
+}
+
+// invalidAwardHandler 总是返回事件校验错误
+type invalidAwardHandler struct {
+	calls int
+}
+
+func (h *invalidAwardHandler) Handle(event notification.Event) error {
+	h.calls++
+	return &notification.ValidationError{EventType: event.GetType(), Field: "award_amount", Reason: "rejected by handler"}
+}
+
+func (h *invalidAwardHandler) SupportEventType() notification.EventType {
+	return notification.EventTypeAward
+}
+
+func TestReceiptDeadLetteredWithoutRetryOnInvalidEvent(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := notification.NewEventDispatcher(ctx, 10)
+	h := &invalidAwardHandler{}
+	dispatcher.RegisterHandler(h)
+	dispatcher.Start(1)
+	defer dispatcher.Stop()
+
+	receipt, err := dispatcher.DispatchWithReceipt(notification.NewAwardEvent(ctx, 1, "MS001", 100, "cash"))
+	if err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	// 重试的退避至少1秒，未重试时应很快结束
+	waitCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+	err = receipt.Wait(waitCtx)
+	if !errors.Is(err, notification.ErrInvalidEvent) || receipt.Status() != notification.ReceiptDeadLettered || h.calls != 1 {
+		t.Fatalf("receipt = %s, %v, handled %d times", receipt.Status(), err, h.calls)
+	}
+}
+
+func TestReceiptRejectsDuplicatePendingEventID(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := notification.NewEventDispatcher(ctx, 10)
+	recorder := &awardRecorder{}
+	dispatcher.RegisterHandler(recorder)
+
+	// worker启动前第一次分发的回执一直处于等待状态
+	event := notification.NewAwardEvent(ctx, 1, "MS001", 100, "cash")
+	receipt, err := dispatcher.DispatchWithReceipt(event)
+	if err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if _, err := dispatcher.DispatchWithReceipt(event); !errors.Is(err, notification.ErrDuplicateEventID) {
+		t.Fatalf("dispatch duplicate = %v, want ErrDuplicateEventID", err)
+	}
+
+	dispatcher.Start(1)
+	defer dispatcher.Stop()
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := receipt.Wait(waitCtx); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if receipt.Status() != notification.ReceiptHandled || len(recorder.events) != 1 {
+		t.Fatalf("receipt = %s, handled %d events", receipt.Status(), len(recorder.events))
+	}
+	// 回执结束后同一事件ID可以再次分发
+	if _, err := dispatcher.DispatchWithReceipt(event); err != nil {
+		t.Fatalf("dispatch after resolved: %v", err)
+	}
+}
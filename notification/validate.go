@@ -0,0 +1,143 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereal3x/notice/constants"
+)
+
+// ErrInvalidEvent 事件校验失败，可通过errors.Is判断
+// handler返回该错误表示事件无法处理，分发器不再重试，直接进入死信
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Validator 可在入队前自校验的事件
+type Validator interface {
+	Validate() error
+}
+
+// PayloadValidator 按子类型校验通用事件的payload，generic.Registry实现了该接口
+type PayloadValidator interface {
+	Validate(subtype string, payload map[string]any) error
+}
+
+// ValidationError 事件校验错误
+type ValidationError struct {
+	EventType EventType // 事件类型
+	Field     string    // 不合法的字段
+	Reason    string    // 原因
+	Err       error     // 底层错误，可为nil
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s event: %s %s", e.EventType, e.Field, e.Reason)
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidEvent
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func invalid(eventType EventType, field, reason string) *ValidationError {
+	return &ValidationError{EventType: eventType, Field: field, Reason: reason}
+}
+
+// validateBase 校验所有事件共有的字段
+func validateBase(e BaseEvent) error {
+	if e.Account <= 0 {
+		return invalid(e.Type, "account", "must be positive")
+	}
+	return nil
+}
+
+func isManuscriptStatus(status int8) bool {
+	switch status {
+	case constants.MANUSCRIPT_AUDIT_STATUS_PENDING,
+		constants.MANUSCRIPT_AUDIT_STATUS_APPROVED,
+		constants.MANUSCRIPT_AUDIT_STATUS_REJECTED:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *ManuscriptEvent) Validate() error {
+	if err := validateBase(e.BaseEvent); err != nil {
+		return err
+	}
+	if e.ManuscriptId == "" {
+		return invalid(e.Type, "manuscript_id", "is empty")
+	}
+	if !isManuscriptStatus(e.OldStatus) {
+		return invalid(e.Type, "old_status", fmt.Sprintf("unknown status %d", e.OldStatus))
+	}
+	if !isManuscriptStatus(e.NewStatus) {
+		return invalid(e.Type, "new_status", fmt.Sprintf("unknown status %d", e.NewStatus))
+	}
+	if e.OldStatus == e.NewStatus {
+		return invalid(e.Type, "new_status", "equals old_status")
+	}
+	return nil
+}
+
+func (e *AwardEvent) Validate() error {
+	if err := validateBase(e.BaseEvent); err != nil {
+		return err
+	}
+	if e.ManuscriptId == "" {
+		return invalid(e.Type, "manuscript_id", "is empty")
+	}
+	if e.AwardAmount < 0 {
+		return invalid(e.Type, "award_amount", "must not be negative")
+	}
+	return nil
+}
+
+func (e *CertificationEvent) Validate() error {
+	if err := validateBase(e.BaseEvent); err != nil {
+		return err
+	}
+	if e.CertificationId == "" {
+		return invalid(e.Type, "certification_id", "is empty")
+	}
+	if e.Status != constants.CERTIFICATION_AUDIT_STATUS_APPROVED && e.Status != constants.CERTIFICATION_AUDIT_STATUS_REJECTED {
+		return invalid(e.Type, "status", fmt.Sprintf("unknown status %d", e.Status))
+	}
+	return nil
+}
+
+func (e *GenericEvent) Validate() error {
+	if err := validateBase(e.BaseEvent); err != nil {
+		return err
+	}
+	if e.Subtype == "" {
+		return invalid(e.Type, "subtype", "is empty")
+	}
+	return nil
+}
+
+func (e *DeliveryEvent) Validate() error {
+	if err := validateBase(e.BaseEvent); err != nil {
+		return err
+	}
+	if e.Channel == "" {
+		return invalid(e.Type, "channel", "is empty")
+	}
+	if e.Content == "" {
+		return invalid(e.Type, "content", "is empty")
+	}
+	return nil
+}
+
+func (e *DigestEvent) Validate() error {
+	if err := validateBase(e.BaseEvent); err != nil {
+		return err
+	}
+	if len(e.Manuscripts)+len(e.Awards) == 0 {
+		return invalid(e.Type, "events", "is empty")
+	}
+	return nil
+}
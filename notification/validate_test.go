@@ -0,0 +1,51 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ethereal3x/notice/generic"
+	"github.com/ethereal3x/notice/notification"
+)
+
+func TestDispatchRejectsInvalidGenericPayload(t *testing.T) {
+	ctx := context.Background()
+	registry := generic.NewRegistry()
+	err := registry.Register(&generic.Definition{
+		Subtype:    "activity_reminder",
+		NotifyType: 10,
+		Schema:     json.RawMessage(`{"type": "object", "required": ["activity_name"], "properties": {"activity_name": {"type": "string"}}}`),
+		Templates:  []generic.TemplateDefinition{{Title: "reminder", Content: "{{.Payload.activity_name}}"}},
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	dispatcher := notification.NewEventDispatcher(ctx, 10).WithPayloadValidator(registry)
+
+	tests := []struct {
+		name    string
+		subtype string
+		payload map[string]any
+		cause   error
+	}{
+		{"unknown subtype", "missing", map[string]any{"activity_name": "spring"}, generic.ErrUnknownSubtype},
+		{"missing field", "activity_reminder", map[string]any{}, generic.ErrInvalidPayload},
+		{"wrong type", "activity_reminder", map[string]any{"activity_name": 1}, generic.ErrInvalidPayload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := dispatcher.Dispatch(notification.NewGenericEvent(ctx, 1, tt.subtype, tt.payload))
+			if !errors.Is(err, notification.ErrInvalidEvent) || !errors.Is(err, tt.cause) {
+				t.Fatalf("Dispatch = %v, want ErrInvalidEvent wrapping %v", err, tt.cause)
+			}
+		})
+	}
+	if n := dispatcher.GetEventChannelLen(); n != 0 {
+		t.Fatalf("queue length = %d, want 0", n)
+	}
+	if err := dispatcher.Dispatch(notification.NewGenericEvent(ctx, 1, "activity_reminder", map[string]any{"activity_name": "spring"})); err != nil {
+		t.Fatalf("Dispatch(valid) = %v", err)
+	}
+}
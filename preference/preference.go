@@ -0,0 +1,174 @@
+package preference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/constants"
+	"github.com/ethereal3x/notice/generic"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/repo"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidNotifyType = errors.New("invalid notification type")
+	ErrInvalidChannel    = errors.New("invalid channel")
+	ErrInvalidReroute    = errors.New("invalid reroute")
+)
+
+// validChannels 支持订阅的渠道
+var validChannels = map[channel.Channel]bool{
+	channel.ChannelInbox: true,
+	channel.ChannelSMS:   true,
+	channel.ChannelEmail: true,
+}
+
+// Action 偏好判定结果
+type Action int
+
+const (
+	ActionDeliver Action = iota // 正常投递
+	ActionSkip                  // 跳过
+	ActionReroute               // 改投其他渠道
+)
+
+// Decision 某条通知在某个渠道上的投递决定
+type Decision struct {
+	Action  Action
+	Channel channel.Channel // ActionReroute时的目标渠道
+}
+
+// Service 用户通知偏好服务
+type Service struct {
+	repo     *repo.PreferenceRepository
+	registry *generic.Registry
+}
+
+func NewService(repo *repo.PreferenceRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// WithRegistry 设置通用通知注册表，注册表中的通知类型（包括活动推送）也可以设置偏好
+func (s *Service) WithRegistry(registry *generic.Registry) *Service {
+	s.registry = registry
+	return s
+}
+
+// GetPreferences 查询账号的全部偏好，未设置的类型/渠道默认接收
+func (s *Service) GetPreferences(ctx context.Context, accountID int64) ([]*repo.NotificationPreference, error) {
+	return s.repo.GetPreferencesByAccountID(ctx, accountID)
+}
+
+// UpdatePreference 更新账号在某类型某渠道上的偏好，reroute为空表示不改投
+// 改投只在外部渠道之间生效：站内信由业务handler直接写入，不能改投到其他渠道，
+// 也没有sender处理投递到站内信的事件，因此拒绝涉及站内信的改投
+func (s *Service) UpdatePreference(ctx context.Context, accountID int64, notifyType int8, ch channel.Channel, enabled bool, reroute channel.Channel) error {
+	if !s.isValidNotifyType(notifyType) {
+		return fmt.Errorf("%w: %d", ErrInvalidNotifyType, notifyType)
+	}
+	if !validChannels[ch] {
+		return fmt.Errorf("%w: %s", ErrInvalidChannel, ch)
+	}
+	if reroute != "" {
+		if !validChannels[reroute] || reroute == ch {
+			return fmt.Errorf("%w: reroute %s", ErrInvalidChannel, reroute)
+		}
+		if ch == channel.ChannelInbox || reroute == channel.ChannelInbox {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidReroute, ch, reroute)
+		}
+	}
+	return s.repo.UpsertPreference(ctx, &repo.NotificationPreference{
+		AccountID: accountID,
+		Type:      notifyType,
+		Channel:   string(ch),
+		Enabled:   enabled,
+		Reroute:   string(reroute),
+	})
+}
+
+// Decide 判定通知是否投递到指定渠道
+func (s *Service) Decide(ctx context.Context, accountID int64, notifyType int8, ch channel.Channel) (Decision, error) {
+	p, err := s.repo.GetPreference(ctx, accountID, notifyType, string(ch))
+	if err != nil {
+		return Decision{}, err
+	}
+	switch {
+	case p == nil:
+		return Decision{Action: ActionDeliver, Channel: ch}, nil
+	case p.Reroute != "":
+		return Decision{Action: ActionReroute, Channel: channel.Channel(p.Reroute)}, nil
+	case !p.Enabled:
+		return Decision{Action: ActionSkip}, nil
+	default:
+		return Decision{Action: ActionDeliver, Channel: ch}, nil
+	}
+}
+
+// Middleware 返回按用户偏好过滤事件的分发器中间件
+// 外部渠道投递事件支持跳过和改投；站内信事件只支持跳过，跳过后由其派生的投递也不会产生
+// 无法执行的改投（历史数据中涉及站内信的改投）按原渠道投递并记录日志
+// 偏好查询失败时按默认接收处理，避免因偏好存储故障丢失通知
+func Middleware(s *Service) notification.Middleware {
+	return func(next notification.HandleFunc) notification.HandleFunc {
+		return func(event notification.Event) error {
+			routable, ok := event.(notification.Routable)
+			if !ok {
+				return next(event)
+			}
+			ctx := event.GetContext()
+			decision, err := s.Decide(ctx, event.GetAccountID(), routable.GetNotifyType(), routable.GetChannel())
+			if err != nil {
+				logger.ContextWarn(ctx, "preference.Middleware: query preference failed, deliver by default",
+					zap.Int64("account_id", event.GetAccountID()),
+					zap.Error(err))
+				return next(event)
+			}
+
+			switch decision.Action {
+			case ActionSkip:
+				logger.ContextDebug(ctx, "preference.Middleware: skipped by user preference",
+					zap.String("event_type", string(event.GetType())),
+					zap.Int64("account_id", event.GetAccountID()),
+					zap.Int8("notify_type", routable.GetNotifyType()),
+					zap.String("channel", string(routable.GetChannel())))
+				return nil
+			case ActionReroute:
+				delivery, ok := event.(*notification.DeliveryEvent)
+				if !ok || decision.Channel == channel.ChannelInbox {
+					logger.ContextWarn(ctx, "preference.Middleware: reroute cannot be applied, deliver on original channel",
+						zap.String("event_type", string(event.GetType())),
+						zap.Int64("account_id", event.GetAccountID()),
+						zap.String("from", string(routable.GetChannel())),
+						zap.String("to", string(decision.Channel)))
+					return next(event)
+				}
+				rerouted := *delivery
+				rerouted.Channel = decision.Channel
+				logger.ContextDebug(ctx, "preference.Middleware: rerouted by user preference",
+					zap.Int64("account_id", event.GetAccountID()),
+					zap.String("from", string(delivery.Channel)),
+					zap.String("to", string(decision.Channel)))
+				return next(&rerouted)
+			default:
+				return next(event)
+			}
+		}
+	}
+}
+
+// isValidNotifyType 内置通知类型或通用通知注册表中的类型
+func (s *Service) isValidNotifyType(notifyType int8) bool {
+	switch notifyType {
+	case constants.NOTIFICATION_TYPE_MANUSCRIPT_AUDIT,
+		constants.NOTIFICATION_TYPE_CERTIFICATION_AUDIT,
+		constants.NOTIFICATION_TYPE_REWARD_DISTRIBUTE,
+		constants.NOTIFICATION_TYPE_DIGEST:
+		return true
+	default:
+		return s.registry != nil && s.registry.HasNotifyType(notifyType)
+	}
+}
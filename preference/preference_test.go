@@ -0,0 +1,93 @@
+package preference_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/constants"
+	"github.com/ethereal3x/notice/generic"
+	"github.com/ethereal3x/notice/preference"
+	"github.com/ethereal3x/notice/repo"
+)
+
+func newService(t *testing.T) *preference.Service {
+	t.Helper()
+	db, err := repo.NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	return preference.NewService(repo.NewPreferenceRepository(db))
+}
+
+func TestUpdatePreferenceRejectsInboxReroute(t *testing.T) {
+	ctx := context.Background()
+	s := newService(t)
+	notifyType := constants.NOTIFICATION_TYPE_REWARD_DISTRIBUTE
+
+	cases := []struct {
+		from, to channel.Channel
+	}{
+		{channel.ChannelSMS, channel.ChannelInbox},
+		{channel.ChannelInbox, channel.ChannelEmail},
+	}
+	for _, c := range cases {
+		err := s.UpdatePreference(ctx, 1, notifyType, c.from, true, c.to)
+		if !errors.Is(err, preference.ErrInvalidReroute) {
+			t.Errorf("reroute %s to %s: err = %v, want ErrInvalidReroute", c.from, c.to, err)
+		}
+	}
+
+	if err := s.UpdatePreference(ctx, 1, notifyType, channel.ChannelSMS, true, channel.ChannelEmail); err != nil {
+		t.Fatalf("reroute sms to email: %v", err)
+	}
+	decision, err := s.Decide(ctx, 1, notifyType, channel.ChannelSMS)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if decision.Action != preference.ActionReroute || decision.Channel != channel.ChannelEmail {
+		t.Fatalf("decision = %+v, want reroute to email", decision)
+	}
+}
+
+func TestDecideOptOutOnFirstUpdate(t *testing.T) {
+	ctx := context.Background()
+	s := newService(t)
+	notifyType := constants.NOTIFICATION_TYPE_MANUSCRIPT_AUDIT
+
+	if err := s.UpdatePreference(ctx, 1, notifyType, channel.ChannelEmail, false, ""); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	decision, err := s.Decide(ctx, 1, notifyType, channel.ChannelEmail)
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if decision.Action != preference.ActionSkip {
+		t.Fatalf("decision = %+v, want skip", decision)
+	}
+}
+
+func TestUpdatePreferenceAcceptsGenericTypes(t *testing.T) {
+	ctx := context.Background()
+	registry := generic.NewRegistry()
+	if err := registry.Register(&generic.Definition{Subtype: "activity_reminder", NotifyType: 10}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	s := newService(t)
+	if err := s.UpdatePreference(ctx, 1, 10, channel.ChannelInbox, false, ""); !errors.Is(err, preference.ErrInvalidNotifyType) {
+		t.Fatalf("update without registry: err = %v, want ErrInvalidNotifyType", err)
+	}
+
+	s.WithRegistry(registry)
+	if err := s.UpdatePreference(ctx, 1, 10, channel.ChannelInbox, false, ""); err != nil {
+		t.Fatalf("update generic type: %v", err)
+	}
+	if err := s.UpdatePreference(ctx, 1, 11, channel.ChannelInbox, false, ""); !errors.Is(err, preference.ErrInvalidNotifyType) {
+		t.Fatalf("update unregistered type: err = %v, want ErrInvalidNotifyType", err)
+	}
+	decision, err := s.Decide(ctx, 1, 10, channel.ChannelInbox)
+	if err != nil || decision.Action != preference.ActionSkip {
+		t.Fatalf("decide = %+v, %v, want skip", decision, err)
+	}
+}
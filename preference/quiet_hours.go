@@ -0,0 +1,150 @@
+package preference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/notification"
+	"github.com/ethereal3x/notice/repo"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidQuietHours = errors.New("invalid quiet hours")
+
+// QuietHoursService 免打扰时段服务
+type QuietHoursService struct {
+	repo   *repo.QuietHoursRepository
+	urgent map[int8]bool
+}
+
+// NewQuietHoursService 创建免打扰服务，urgentTypes中的通知类型不受免打扰限制
+func NewQuietHoursService(repo *repo.QuietHoursRepository, urgentTypes ...int8) *QuietHoursService {
+	urgent := make(map[int8]bool, len(urgentTypes))
+	for _, t := range urgentTypes {
+		urgent[t] = true
+	}
+	return &QuietHoursService{repo: repo, urgent: urgent}
+}
+
+// GetQuietHours 查询账号免打扰设置，未设置时返回nil
+func (s *QuietHoursService) GetQuietHours(ctx context.Context, accountID int64) (*repo.QuietHours, error) {
+	return s.repo.GetQuietHours(ctx, accountID)
+}
+
+// UpdateQuietHours 更新账号免打扰设置，start/end格式为"HH:MM"，timezone为IANA时区名
+func (s *QuietHoursService) UpdateQuietHours(ctx context.Context, accountID int64, start, end, timezone string, enabled bool) error {
+	startMinute, err := parseClock(start)
+	if err != nil {
+		return err
+	}
+	endMinute, err := parseClock(end)
+	if err != nil {
+		return err
+	}
+	if startMinute == endMinute {
+		return fmt.Errorf("%w: start equals end", ErrInvalidQuietHours)
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("%w: timezone %s", ErrInvalidQuietHours, timezone)
+	}
+	return s.repo.UpsertQuietHours(ctx, &repo.QuietHours{
+		AccountID:   accountID,
+		StartMinute: startMinute,
+		EndMinute:   endMinute,
+		Timezone:    timezone,
+		Enabled:     enabled,
+	})
+}
+
+// DeferUntil 若账号当前处于免打扰时段，返回时段结束时间
+func (s *QuietHoursService) DeferUntil(ctx context.Context, accountID int64, notifyType int8, now time.Time) (time.Time, bool, error) {
+	if s.urgent[notifyType] {
+		return time.Time{}, false, nil
+	}
+	q, err := s.repo.GetQuietHours(ctx, accountID)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if q == nil || !q.Enabled {
+		return time.Time{}, false, nil
+	}
+	end, in := windowEnd(q, now)
+	return end, in, nil
+}
+
+// QuietHoursMiddleware 返回免打扰中间件
+// 站内信照常写入，外部渠道的投递在免打扰时段内延迟到时段结束后重新入队
+// 分发器需通过WithDelayStore配置持久化存储，否则延迟的投递在重启时丢失
+func QuietHoursMiddleware(s *QuietHoursService, dispatcher *notification.EventDispatcher) notification.Middleware {
+	return func(next notification.HandleFunc) notification.HandleFunc {
+		return func(event notification.Event) error {
+			routable, ok := event.(notification.Routable)
+			if !ok || routable.GetChannel() == channel.ChannelInbox {
+				return next(event)
+			}
+			ctx := event.GetContext()
+			until, quiet, err := s.DeferUntil(ctx, event.GetAccountID(), routable.GetNotifyType(), time.Now())
+			if err != nil {
+				logger.ContextWarn(ctx, "preference.QuietHoursMiddleware: query quiet hours failed, deliver now",
+					zap.Int64("account_id", event.GetAccountID()),
+					zap.Error(err))
+				return next(event)
+			}
+			if !quiet {
+				return next(event)
+			}
+
+			logger.ContextDebug(ctx, "preference.QuietHoursMiddleware: deferred until quiet hours end",
+				zap.Int64("account_id", event.GetAccountID()),
+				zap.String("channel", string(routable.GetChannel())),
+				zap.Time("until", until))
+			if err := dispatcher.DispatchAfter(event, time.Until(until)); err != nil {
+				return err
+			}
+			return notification.ErrDeferred
+		}
+	}
+}
+
+// windowEnd 判断now是否处于免打扰时段，是则返回本次时段结束时间
+func windowEnd(q *repo.QuietHours, now time.Time) (time.Time, bool) {
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	endAt := func(dayOffset int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+dayOffset, q.EndMinute/60, q.EndMinute%60, 0, 0, loc)
+	}
+
+	switch {
+	case q.StartMinute < q.EndMinute:
+		// 当天时段，例如 13:00-14:00
+		if minute >= q.StartMinute && minute < q.EndMinute {
+			return endAt(0), true
+		}
+	case q.StartMinute > q.EndMinute:
+		// 跨零点时段，例如 22:00-08:00
+		if minute >= q.StartMinute {
+			return endAt(1), true
+		}
+		if minute < q.EndMinute {
+			return endAt(0), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseClock 解析"HH:MM"为当天分钟数
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidQuietHours, clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
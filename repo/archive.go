@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ArchivedNotification 归档的通知，保留原通知的全部字段
+type ArchivedNotification struct {
+	Notification `gorm:"embedded"`
+	ArchivedAt   time.Time `gorm:"column:archived_at;not null;comment:归档时间" json:"archived_at"`
+}
+
+// TableName 指定表名
+func (ArchivedNotification) TableName() string {
+	return "tbl_notification_archive"
+}
+
+// ArchiveNotices 将指定类型中创建时间早于before的通知（包括已删除的）移入归档表，返回归档行数
+// 提交后通知未读数观察者被移出的未读通知
+// 每次最多处理limit行，复制与删除在同一事务中完成，调用方循环调用直到返回0
+func (r *NoticeRepository) ArchiveNotices(ctx context.Context, notifyType int8, before time.Time, limit int) (int64, error) {
+	var (
+		archived int64
+		notices  []*Notification
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Where("type = ? AND created_at < ?", notifyType, before).
+			Order("id ASC").
+			Limit(limit).
+			Find(&notices).Error
+		if err != nil || len(notices) == 0 {
+			return err
+		}
+
+		now := time.Now()
+		rows := make([]*ArchivedNotification, 0, len(notices))
+		ids := make([]uint64, 0, len(notices))
+		for _, n := range notices {
+			rows = append(rows, &ArchivedNotification{Notification: *n, ArchivedAt: now})
+			ids = append(ids, n.ID)
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(&Notification{})
+		archived = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	notifyArchived(ctx, r.observer, notices)
+	return archived, nil
+}
@@ -0,0 +1,208 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereal3x/notice/constants"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SegmentAll 面向所有用户的广播
+const SegmentAll = ""
+
+// Broadcast 广播通知，只存一份，在用户读取时合并到其通知列表中
+type Broadcast struct {
+	ID        uint64         `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	Type      int8           `gorm:"column:type;not null;comment:通知类型" json:"type"`
+	Category  string         `gorm:"column:category;type:varchar(32);not null;default:'';comment:通知分类" json:"category"`
+	Title     string         `gorm:"column:title;type:varchar(255);not null;comment:通知标题" json:"title"`
+	Content   string         `gorm:"column:content;type:text;not null;comment:通知内容" json:"content"`
+	Segment   string         `gorm:"column:segment;type:varchar(64);not null;default:'';comment:目标用户分群，为空表示所有用户" json:"segment"`
+	ExtData   string         `gorm:"column:ext_data;type:text;comment:扩展数据(JSON格式)" json:"ext_data"`
+	ExpiresAt *time.Time     `gorm:"column:expires_at;comment:过期时间，为空表示不过期" json:"expires_at,omitempty"`
+	CreatedAt time.Time      `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;comment:删除时间" json:"-"`
+}
+
+// TableName 指定表名
+func (Broadcast) TableName() string {
+	return "tbl_notification_broadcast"
+}
+
+// BroadcastRead 用户对广播的已读记录，用户首次读取时才写入
+type BroadcastRead struct {
+	ID          uint64    `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	AccountID   int64     `gorm:"column:account_id;not null;uniqueIndex:uk_account_broadcast;comment:用户账号ID" json:"account_id"`
+	BroadcastID uint64    `gorm:"column:broadcast_id;not null;uniqueIndex:uk_account_broadcast;comment:广播ID" json:"broadcast_id"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime;comment:读取时间" json:"created_at"`
+}
+
+// TableName 指定表名
+func (BroadcastRead) TableName() string {
+	return "tbl_notification_broadcast_read"
+}
+
+// BroadcastFilter 广播查询条件
+type BroadcastFilter struct {
+	Segments []string   // 用户所属的分群，面向所有用户的广播总是包含在内
+	Type     *int8      // 通知类型，为nil时不过滤
+	After    *time.Time // 创建时间下界（含）
+	Before   *time.Time // 创建时间上界（不含）
+}
+
+type BroadcastRepository struct {
+	db *gorm.DB
+}
+
+func NewBroadcastRepository(db *gorm.DB) *BroadcastRepository {
+	return &BroadcastRepository{db: db}
+}
+
+// CreateBroadcast 发布广播
+func (r *BroadcastRepository) CreateBroadcast(ctx context.Context, b *Broadcast) error {
+	return r.db.WithContext(ctx).Create(b).Error
+}
+
+// DeleteBroadcast 撤回广播（软删除），撤回后不再出现在任何用户的列表中
+func (r *BroadcastRepository) DeleteBroadcast(ctx context.Context, id uint64) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&Broadcast{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// activeBroadcasts 用户可见的未过期广播
+func (r *BroadcastRepository) activeBroadcasts(ctx context.Context, filter BroadcastFilter) *gorm.DB {
+	segments := append([]string{SegmentAll}, filter.Segments...)
+	query := r.db.WithContext(ctx).Model(&Broadcast{}).
+		Where("segment IN ?", segments).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	if filter.Type != nil {
+		query = query.Where("type = ?", *filter.Type)
+	}
+	if filter.After != nil {
+		query = query.Where("created_at >= ?", *filter.After)
+	}
+	if filter.Before != nil {
+		query = query.Where("created_at < ?", *filter.Before)
+	}
+	return query
+}
+
+// unreadBy 账号未读的广播
+func unreadBy(accountID int64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("NOT EXISTS (SELECT 1 FROM tbl_notification_broadcast_read r WHERE r.broadcast_id = tbl_notification_broadcast.id AND r.account_id = ?)", accountID)
+	}
+}
+
+// listVisible 从合并列表的位置c开始按翻页方向查询用户可见的广播，最多返回limit条
+// status不为nil时按账号的已读状态过滤；c为nil时从最新的广播开始
+func (r *BroadcastRepository) listVisible(ctx context.Context, accountID int64, filter BroadcastFilter, status *int8, c *inboxCursor, limit int) ([]*Broadcast, error) {
+	query := r.activeBroadcasts(ctx, filter)
+	if status != nil {
+		if *status == constants.NOTIFICATION_STATUS_UNREAD {
+			query = query.Scopes(unreadBy(accountID))
+		} else {
+			query = query.Where("EXISTS (SELECT 1 FROM tbl_notification_broadcast_read r WHERE r.broadcast_id = tbl_notification_broadcast.id AND r.account_id = ?)", accountID)
+		}
+	}
+
+	order := "created_at DESC, id DESC"
+	if c != nil {
+		// 同一时刻的广播排在个人通知之前
+		at := time.Unix(0, c.CreatedAt)
+		switch {
+		case c.Backward && c.Source == sourceBroadcast:
+			query = query.Where("created_at > ? OR (created_at = ? AND id > ?)", at, at, c.ID)
+		case c.Backward:
+			query = query.Where("created_at >= ?", at)
+		case c.Source == sourceBroadcast:
+			query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", at, at, c.ID)
+		default:
+			query = query.Where("created_at < ?", at)
+		}
+		if c.Backward {
+			order = "created_at ASC, id ASC"
+		}
+	}
+
+	var broadcasts []*Broadcast
+	if err := query.Order(order).Limit(limit).Find(&broadcasts).Error; err != nil {
+		return nil, err
+	}
+	return broadcasts, nil
+}
+
+// ListBroadcasts 按创建时间倒序查询用户可见的广播，最多返回limit条
+func (r *BroadcastRepository) ListBroadcasts(ctx context.Context, filter BroadcastFilter, limit int) ([]*Broadcast, error) {
+	var broadcasts []*Broadcast
+	err := r.activeBroadcasts(ctx, filter).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&broadcasts).Error
+	if err != nil {
+		return nil, err
+	}
+	return broadcasts, nil
+}
+
+// GetReadBroadcastIDs 返回ids中账号已读的广播
+func (r *BroadcastRepository) GetReadBroadcastIDs(ctx context.Context, accountID int64, ids []uint64) (map[uint64]bool, error) {
+	read := make(map[uint64]bool)
+	if len(ids) == 0 {
+		return read, nil
+	}
+	var readIDs []uint64
+	err := r.db.WithContext(ctx).Model(&BroadcastRead{}).
+		Where("account_id = ? AND broadcast_id IN ?", accountID, ids).
+		Pluck("broadcast_id", &readIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range readIDs {
+		read[id] = true
+	}
+	return read, nil
+}
+
+// CountUnreadBroadcasts 统计账号可见且未读的广播数
+// filter.After应设为账号创建时间，否则新账号会把历史上的全部广播计为未读
+func (r *BroadcastRepository) CountUnreadBroadcasts(ctx context.Context, accountID int64, filter BroadcastFilter) (int64, error) {
+	var count int64
+	err := r.activeBroadcasts(ctx, filter).
+		Scopes(unreadBy(accountID)).
+		Count(&count).Error
+	return count, err
+}
+
+// GetUnreadBroadcastSummary 按通知类型和分类统计账号可见且未读的广播数
+func (r *BroadcastRepository) GetUnreadBroadcastSummary(ctx context.Context, accountID int64, filter BroadcastFilter) (*UnreadSummary, error) {
+	return scanUnreadSummary(r.activeBroadcasts(ctx, filter).Scopes(unreadBy(accountID)))
+}
+
+// MarkBroadcastsRead 记录账号已读指定广播，已读过的忽略，返回新增的已读数
+func (r *BroadcastRepository) MarkBroadcastsRead(ctx context.Context, accountID int64, ids []uint64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	rows := make([]*BroadcastRead, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, &BroadcastRead{AccountID: accountID, BroadcastID: id})
+	}
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&rows)
+	return result.RowsAffected, result.Error
+}
+
+// MarkAllBroadcastsRead 将账号可见的所有未读广播标记为已读，返回新增的已读数
+func (r *BroadcastRepository) MarkAllBroadcastsRead(ctx context.Context, accountID int64, filter BroadcastFilter) (int64, error) {
+	var ids []uint64
+	err := r.activeBroadcasts(ctx, filter).
+		Scopes(unreadBy(accountID)).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	return r.MarkBroadcastsRead(ctx, accountID, ids)
+}
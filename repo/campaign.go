@@ -0,0 +1,123 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 活动推送状态
+const (
+	CampaignStatusPending   int8 = 0 // 待执行
+	CampaignStatusRunning   int8 = 1 // 执行中
+	CampaignStatusPaused    int8 = 2 // 已暂停，可从游标处继续
+	CampaignStatusCompleted int8 = 3 // 已完成
+	CampaignStatusFailed    int8 = 4 // 执行失败，可从游标处重试
+)
+
+// Campaign 面向分群的批量推送任务，LastAccountID记录已分发的最后一个账号ID用于断点续推
+type Campaign struct {
+	ID            uint64     `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	Name          string     `gorm:"column:name;type:varchar(128);not null;comment:任务名称" json:"name"`
+	Segment       string     `gorm:"column:segment;type:varchar(64);not null;comment:目标分群" json:"segment"`
+	Subtype       string     `gorm:"column:subtype;type:varchar(64);not null;comment:通用通知子类型" json:"subtype"`
+	Payload       string     `gorm:"column:payload;type:text;comment:通知参数(JSON格式)" json:"payload"`
+	Status        int8       `gorm:"column:status;not null;default:0;comment:状态: 0-待执行 1-执行中 2-已暂停 3-已完成 4-失败" json:"status"`
+	LastAccountID int64      `gorm:"column:last_account_id;not null;default:0;comment:已分发的最后一个账号ID" json:"last_account_id"`
+	Dispatched    int64      `gorm:"column:dispatched;not null;default:0;comment:已分发的账号数" json:"dispatched"`
+	LastError     string     `gorm:"column:last_error;type:varchar(512);not null;default:'';comment:最近一次失败原因" json:"last_error"`
+	ClaimedUntil  *time.Time `gorm:"column:claimed_until;comment:执行实例的租约到期时间，到期后可被其他实例接管" json:"claimed_until"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Campaign) TableName() string {
+	return "tbl_notification_campaign"
+}
+
+// DefaultCampaignLease 执行中的任务在租约内没有记录进度时，由其他实例接管
+const DefaultCampaignLease = 5 * time.Minute
+
+type CampaignRepository struct {
+	db    *gorm.DB
+	lease time.Duration
+}
+
+func NewCampaignRepository(db *gorm.DB) *CampaignRepository {
+	return &CampaignRepository{db: db, lease: DefaultCampaignLease}
+}
+
+// WithLease 设置执行任务的租约时长，需大于分发一批账号的耗时
+func (r *CampaignRepository) WithLease(lease time.Duration) *CampaignRepository {
+	if lease > 0 {
+		r.lease = lease
+	}
+	return r
+}
+
+// claimable 可被抢占的任务：待执行、已暂停、失败，或租约已到期的执行中任务
+func claimable(db *gorm.DB, now time.Time, statuses ...int8) *gorm.DB {
+	return db.Where("status IN ? OR (status = ? AND (claimed_until IS NULL OR claimed_until < ?))",
+		statuses, CampaignStatusRunning, now)
+}
+
+func (r *CampaignRepository) CreateCampaign(ctx context.Context, c *Campaign) error {
+	return r.db.WithContext(ctx).Create(c).Error
+}
+
+func (r *CampaignRepository) GetCampaign(ctx context.Context, id uint64) (*Campaign, error) {
+	var c Campaign
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&c).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// UpdateProgress 将游标从from推进到lastAccountID并续期租约，返回是否更新成功
+// 任务已被其他实例接管并推进了游标时返回false，调用方应停止执行
+func (r *CampaignRepository) UpdateProgress(ctx context.Context, id uint64, from, lastAccountID, dispatched int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Campaign{}).
+		Where("id = ? AND status = ? AND last_account_id = ?", id, CampaignStatusRunning, from).
+		Updates(map[string]interface{}{
+			"last_account_id": lastAccountID,
+			"dispatched":      dispatched,
+			"claimed_until":   time.Now().Add(r.lease),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateStatus 更新任务状态，lastError为空时清除失败原因
+func (r *CampaignRepository) UpdateStatus(ctx context.Context, id uint64, status int8, lastError string) error {
+	return r.db.WithContext(ctx).Model(&Campaign{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "last_error": lastError}).Error
+}
+
+// ClaimCampaign 将待执行、已暂停、失败或租约已到期的执行中任务原子地置为执行中并加租约，返回是否抢占成功
+// 任务在租约内执行中或已完成时返回false，多个实例同时执行同一任务时只有一个成功；
+// 执行实例崩溃后任务在租约到期后可被重新抢占，从游标处继续
+func (r *CampaignRepository) ClaimCampaign(ctx context.Context, id uint64) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&Campaign{}).
+		Where("id = ?", id).
+		Scopes(func(db *gorm.DB) *gorm.DB {
+			return claimable(db, now, CampaignStatusPending, CampaignStatusPaused, CampaignStatusFailed)
+		}).
+		Updates(map[string]interface{}{"status": CampaignStatusRunning, "last_error": "", "claimed_until": now.Add(r.lease)})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListRunnableCampaigns 按ID升序返回待执行、已暂停和租约已到期的执行中任务ID，最多limit个；失败的任务需人工确认后再执行
+func (r *CampaignRepository) ListRunnableCampaigns(ctx context.Context, limit int) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.WithContext(ctx).Model(&Campaign{}).
+		Scopes(func(db *gorm.DB) *gorm.DB {
+			return claimable(db, time.Now(), CampaignStatusPending, CampaignStatusPaused)
+		}).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
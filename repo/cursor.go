@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor 分页游标无法解析
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// NoticeFilter 通知列表过滤条件，字段为nil时不过滤
+type NoticeFilter struct {
+	Type   *int8
+	Status *int8
+}
+
+// NoticePage 一页通知，按(created_at, id)倒序排列
+type NoticePage struct {
+	Notices    []*Notification `json:"notices"`
+	NextCursor string          `json:"next_cursor"` // 更早一页的游标，没有更早的通知时为空
+	PrevCursor string          `json:"prev_cursor"` // 更新一页的游标，已是最新一页时为空
+}
+
+// cursor 游标位置，对调用方不透明
+type cursor struct {
+	CreatedAt int64  `json:"t"`
+	ID        uint64 `json:"id"`
+	Backward  bool   `json:"b,omitempty"` // 向更新的方向翻页
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func encodeCursor(n *Notification, backward bool) string {
+	return cursor{CreatedAt: n.CreatedAt.UnixNano(), ID: n.ID, Backward: backward}.encode()
+}
+
+func decodeCursor(token string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// ListNotices 按(created_at, id)键集分页查询账号的通知
+// token为空时返回最新一页，否则传入上一页返回的NextCursor或PrevCursor；
+// 翻页期间新到达的通知不会导致重复或遗漏
+func (r *NoticeRepository) ListNotices(ctx context.Context, accountID int64, filter NoticeFilter, token string, limit int) (*NoticePage, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	// account_id与status条件可使用idx_account_status索引
+	query := r.db.WithContext(ctx).Where("account_id = ?", accountID).Scopes(notExpired)
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.Type != nil {
+		query = query.Where("type = ?", *filter.Type)
+	}
+
+	var c *cursor
+	if token != "" {
+		var err error
+		if c, err = decodeCursor(token); err != nil {
+			return nil, err
+		}
+		at := time.Unix(0, c.CreatedAt)
+		if c.Backward {
+			query = query.Where("created_at > ? OR (created_at = ? AND id > ?)", at, at, c.ID).
+				Order("created_at ASC, id ASC")
+		} else {
+			query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", at, at, c.ID).
+				Order("created_at DESC, id DESC")
+		}
+	} else {
+		query = query.Order("created_at DESC, id DESC")
+	}
+
+	// 多取一条判断是否还有下一页
+	var notices []*Notification
+	if err := query.Limit(limit + 1).Find(&notices).Error; err != nil {
+		return nil, err
+	}
+	return newNoticePage(c, notices, limit), nil
+}
+
+// newNoticePage 由按游标方向排序、多取一条的查询结果生成一页通知
+func newNoticePage(c *cursor, notices []*Notification, limit int) *NoticePage {
+	hasMore := len(notices) > limit
+	page := &NoticePage{}
+	if hasMore {
+		notices = notices[:limit]
+	}
+	page.Notices = notices
+
+	if c != nil && c.Backward {
+		for i, j := 0, len(notices)-1; i < j; i, j = i+1, j-1 {
+			notices[i], notices[j] = notices[j], notices[i]
+		}
+		if len(notices) == 0 {
+			return page
+		}
+		if hasMore {
+			page.PrevCursor = encodeCursor(notices[0], true)
+		}
+		page.NextCursor = encodeCursor(notices[len(notices)-1], false)
+		return page
+	}
+
+	if len(notices) == 0 {
+		return page
+	}
+	if hasMore {
+		page.NextCursor = encodeCursor(notices[len(notices)-1], false)
+	}
+	if c != nil {
+		page.PrevCursor = encodeCursor(notices[0], true)
+	}
+	return page
+}
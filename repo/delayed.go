@@ -0,0 +1,104 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DelayedEvent 等待到期后重新入队的事件，例如免打扰时段内延迟的外部渠道投递
+type DelayedEvent struct {
+	ID           uint64     `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	AccountID    int64      `gorm:"column:account_id;not null;comment:用户账号ID" json:"account_id"`
+	Payload      []byte     `gorm:"column:payload;type:blob;not null;comment:序列化后的事件" json:"payload"`
+	DueAt        time.Time  `gorm:"column:due_at;not null;comment:到期时间" json:"due_at"`
+	ClaimedUntil *time.Time `gorm:"column:claimed_until;comment:被实例取出后的租约到期时间，到期未完成时可被重新取出" json:"claimed_until"`
+	CreatedAt    time.Time  `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+}
+
+// TableName 指定表名
+func (DelayedEvent) TableName() string {
+	return "tbl_notification_delayed"
+}
+
+// DefaultDelayLease 取出的延迟事件在租约内未完成时，由其他实例重新取出
+const DefaultDelayLease = time.Minute
+
+type DelayedEventRepository struct {
+	db    *gorm.DB
+	lease time.Duration
+}
+
+func NewDelayedEventRepository(db *gorm.DB) *DelayedEventRepository {
+	return &DelayedEventRepository{db: db, lease: DefaultDelayLease}
+}
+
+// WithLease 设置取出事件的租约时长，需大于处理一批事件的耗时
+func (r *DelayedEventRepository) WithLease(lease time.Duration) *DelayedEventRepository {
+	if lease > 0 {
+		r.lease = lease
+	}
+	return r
+}
+
+// SaveDelayed 保存事件，dueAt后可被取出
+func (r *DelayedEventRepository) SaveDelayed(ctx context.Context, accountID int64, payload []byte, dueAt time.Time) error {
+	return r.db.WithContext(ctx).Create(&DelayedEvent{AccountID: accountID, Payload: payload, DueAt: dueAt}).Error
+}
+
+// ClaimDelayed 取出最多limit个已到期的事件依次交给fn，fn返回nil后删除，返回错误时释放等待下次取出
+// 每个事件先通过条件更新加租约，多个实例同时取出时同一事件只会交给一个实例；
+// 实例在租约内崩溃时事件会在租约到期后被重新取出，因此可能重复投递但不会丢失。返回成功处理的个数
+func (r *DelayedEventRepository) ClaimDelayed(ctx context.Context, now time.Time, limit int, fn func(payload []byte) error) (int, error) {
+	var due []*DelayedEvent
+	err := r.db.WithContext(ctx).
+		Where("due_at <= ? AND (claimed_until IS NULL OR claimed_until < ?)", now, now).
+		Order("due_at ASC").
+		Limit(limit).
+		Find(&due).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		handled int
+		errs    []error
+	)
+	for _, e := range due {
+		result := r.db.WithContext(ctx).Model(&DelayedEvent{}).
+			Where("id = ? AND (claimed_until IS NULL OR claimed_until < ?)", e.ID, now).
+			Update("claimed_until", now.Add(r.lease))
+		if result.Error != nil {
+			errs = append(errs, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			// 已被其他实例取出
+			continue
+		}
+
+		if err := fn(e.Payload); err != nil {
+			errs = append(errs, err)
+			if err := r.db.WithContext(ctx).Model(&DelayedEvent{}).Where("id = ?", e.ID).
+				Update("claimed_until", nil).Error; err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := r.db.WithContext(ctx).Delete(&DelayedEvent{}, e.ID).Error; err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		handled++
+	}
+	return handled, errors.Join(errs...)
+}
+
+// CountDelayed 统计尚未重新入队的延迟事件数
+func (r *DelayedEventRepository) CountDelayed(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&DelayedEvent{}).Count(&count).Error
+	return count, err
+}
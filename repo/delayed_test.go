@@ -0,0 +1,96 @@
+package repo_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereal3x/notice/repo"
+)
+
+func TestClaimDelayedOnlyDueEvents(t *testing.T) {
+	ctx := context.Background()
+	delayed := repo.NewDelayedEventRepository(newSQLiteDB(t))
+	now := time.Now()
+
+	if err := delayed.SaveDelayed(ctx, 1, []byte("due"), now.Add(-time.Minute)); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := delayed.SaveDelayed(ctx, 1, []byte("later"), now.Add(time.Hour)); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	var got []string
+	handled, err := delayed.ClaimDelayed(ctx, now, 10, func(payload []byte) error {
+		got = append(got, string(payload))
+		return nil
+	})
+	if err != nil || handled != 1 || len(got) != 1 || got[0] != "due" {
+		t.Fatalf("claim = %d %v %v, want only the due event", handled, got, err)
+	}
+	if count, _ := delayed.CountDelayed(ctx); count != 1 {
+		t.Fatalf("remaining = %d, want 1", count)
+	}
+}
+
+func TestClaimDelayedKeepsFailedEvents(t *testing.T) {
+	ctx := context.Background()
+	delayed := repo.NewDelayedEventRepository(newSQLiteDB(t))
+	now := time.Now()
+	if err := delayed.SaveDelayed(ctx, 1, []byte("event"), now); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	failure := errors.New("queue full")
+	_, err := delayed.ClaimDelayed(ctx, now, 10, func([]byte) error { return failure })
+	if !errors.Is(err, failure) {
+		t.Fatalf("claim err = %v, want %v", err, failure)
+	}
+	handled, err := delayed.ClaimDelayed(ctx, now, 10, func([]byte) error { return nil })
+	if err != nil || handled != 1 {
+		t.Fatalf("retry = %d %v, want the released event", handled, err)
+	}
+}
+
+func TestClaimDelayedConcurrentClaimsOnce(t *testing.T) {
+	ctx := context.Background()
+	delayed := repo.NewDelayedEventRepository(newSQLiteDB(t))
+	now := time.Now()
+	for i := 0; i < 20; i++ {
+		if err := delayed.SaveDelayed(ctx, int64(i+1), []byte{byte(i)}, now); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[byte]int)
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := delayed.ClaimDelayed(ctx, now, 20, func(payload []byte) error {
+				mu.Lock()
+				seen[payload[0]]++
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				t.Errorf("claim: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != 20 {
+		t.Fatalf("claimed %d events, want 20", len(seen))
+	}
+	for payload, times := range seen {
+		if times != 1 {
+			t.Errorf("event %d claimed %d times", payload, times)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrUnknownEngagement 不支持的互动类型
+var ErrUnknownEngagement = errors.New("unknown engagement action")
+
+// EngagementAction 用户对通知的互动类型
+type EngagementAction string
+
+const (
+	EngagementRead    EngagementAction = "read"    // 阅读
+	EngagementClick   EngagementAction = "click"   // 点击跳转
+	EngagementDismiss EngagementAction = "dismiss" // 忽略
+)
+
+// engagementColumns 互动类型对应的时间字段
+var engagementColumns = map[EngagementAction]string{
+	EngagementRead:    "read_at",
+	EngagementClick:   "clicked_at",
+	EngagementDismiss: "dismissed_at",
+}
+
+// RecordEngagement 记录用户对通知的互动，每种互动只保留首次时间
+// 点击和忽略同时视为已读；通知不存在或不属于该账号时返回false
+func (r *NoticeRepository) RecordEngagement(ctx context.Context, accountID int64, id uint64, action EngagementAction) (bool, error) {
+	column, exist := engagementColumns[action]
+	if !exist {
+		return false, ErrUnknownEngagement
+	}
+
+	marked, err := r.MarkReadByIDs(ctx, accountID, []uint64{id})
+	if err != nil {
+		return false, err
+	}
+	// 首次阅读时标记已读的同时已写入read_at，不再需要确认通知是否存在
+	if marked > 0 && action == EngagementRead {
+		return true, nil
+	}
+	if action != EngagementRead {
+		result := r.db.WithContext(ctx).Model(&Notification{}).
+			Where("account_id = ? AND id = ? AND "+column+" IS NULL", accountID, id).
+			Update(column, time.Now())
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return true, nil
+		}
+	}
+
+	// 已记录过该互动或通知不存在，再确认通知是否存在
+	var count int64
+	err = r.db.WithContext(ctx).Model(&Notification{}).
+		Where("account_id = ? AND id = ?", accountID, id).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// EngagementStat 某一通知类型和活动的互动统计
+type EngagementStat struct {
+	Type         int8    `json:"type"`
+	ActivityName string  `json:"activity_name"`
+	Delivered    int64   `json:"delivered"`  // 送达数
+	Read         int64   `json:"read"`       // 阅读数
+	Clicked      int64   `json:"clicked"`    // 点击数
+	Dismissed    int64   `json:"dismissed"`  // 忽略数
+	ReadRate     float64 `json:"read_rate"`  // 阅读数/送达数
+	ClickRate    float64 `json:"click_rate"` // 点击数/阅读数
+}
+
+// GetEngagementReport 统计[from, to)期间创建的通知按类型和活动的送达、阅读、点击情况
+// 用户已删除的通知同样计入；广播只记录账号是否已读，不在统计范围内
+func (r *NoticeRepository) GetEngagementReport(ctx context.Context, from, to time.Time) ([]*EngagementStat, error) {
+	var stats []*EngagementStat
+	err := r.db.WithContext(ctx).Unscoped().Model(&Notification{}).
+		Select("type, activity_name, COUNT(*) AS delivered, COUNT(read_at) AS `read`, COUNT(clicked_at) AS clicked, COUNT(dismissed_at) AS dismissed").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("type, activity_name").
+		Order("type, activity_name").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range stats {
+		if stat.Delivered > 0 {
+			stat.ReadRate = float64(stat.Read) / float64(stat.Delivered)
+		}
+		if stat.Read > 0 {
+			stat.ClickRate = float64(stat.Clicked) / float64(stat.Read)
+		}
+	}
+	return stats, nil
+}
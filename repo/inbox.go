@@ -0,0 +1,243 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ethereal3x/notice/constants"
+)
+
+// 合并列表中同一时刻的广播排在个人通知之前
+const (
+	sourceNotice    int8 = 0
+	sourceBroadcast int8 = 1
+)
+
+// InboxItem 用户通知列表中的一项，来自个人通知或广播
+type InboxItem struct {
+	Notice    *Notification `json:"notice,omitempty"`
+	Broadcast *Broadcast    `json:"broadcast,omitempty"`
+	Read      bool          `json:"read"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+func (item *InboxItem) position() inboxCursor {
+	if item.Broadcast != nil {
+		return inboxCursor{CreatedAt: item.CreatedAt.UnixNano(), Source: sourceBroadcast, ID: item.Broadcast.ID}
+	}
+	return inboxCursor{CreatedAt: item.CreatedAt.UnixNano(), Source: sourceNotice, ID: item.Notice.ID}
+}
+
+// InboxPage 一页合并后的通知，按(created_at, 来源, id)倒序排列
+type InboxPage struct {
+	Items      []*InboxItem `json:"items"`
+	NextCursor string       `json:"next_cursor"` // 更早一页的游标，没有更早的通知时为空
+	PrevCursor string       `json:"prev_cursor"` // 更新一页的游标，已是最新一页时为空
+}
+
+// inboxCursor 合并列表中的位置，对调用方不透明
+type inboxCursor struct {
+	CreatedAt int64  `json:"t"`
+	Source    int8   `json:"s"`
+	ID        uint64 `json:"id"`
+	Backward  bool   `json:"b,omitempty"` // 向更新的方向翻页
+}
+
+func (c inboxCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeInboxCursor(token string) (*inboxCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c inboxCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 ||
+		(c.Source != sourceNotice && c.Source != sourceBroadcast) {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// before 合并列表中c是否排在o之后（更早）
+func (c inboxCursor) before(o inboxCursor) bool {
+	if c.CreatedAt != o.CreatedAt {
+		return c.CreatedAt < o.CreatedAt
+	}
+	if c.Source != o.Source {
+		return c.Source < o.Source
+	}
+	return c.ID < o.ID
+}
+
+// noticeCursor 换算为个人通知列表的游标
+// 位置是广播时同一时刻的个人通知都排在其后，向后翻页包含、向前翻页排除这些通知
+func (c inboxCursor) noticeCursor() string {
+	id := c.ID
+	if c.Source == sourceBroadcast {
+		id = math.MaxInt64
+	}
+	return cursor{CreatedAt: c.CreatedAt, ID: id, Backward: c.Backward}.encode()
+}
+
+// Audience 账号的广播可见范围
+type Audience struct {
+	Segments []string  // 账号所属的分群，面向所有用户的广播总是包含在内
+	Since    time.Time // 账号创建时间，此前发布的广播对账号不可见，不计入未读；为零值时不限制
+}
+
+func (a Audience) filter(notifyType *int8) BroadcastFilter {
+	filter := BroadcastFilter{Segments: a.Segments, Type: notifyType}
+	if !a.Since.IsZero() {
+		since := a.Since
+		filter.After = &since
+	}
+	return filter
+}
+
+// Inbox 用户收件箱，读取时将广播合并到个人通知中
+type Inbox struct {
+	notices    NotificationStore
+	broadcasts *BroadcastRepository
+}
+
+func NewInbox(notices NotificationStore, broadcasts *BroadcastRepository) *Inbox {
+	return &Inbox{notices: notices, broadcasts: broadcasts}
+}
+
+// List 按(created_at, 来源, id)键集分页查询账号的个人通知与可见广播合并后的列表
+// token为空时返回最新一页，否则传入上一页返回的NextCursor或PrevCursor
+func (i *Inbox) List(ctx context.Context, accountID int64, audience Audience, filter NoticeFilter, token string, limit int) (*InboxPage, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	var c *inboxCursor
+	noticeToken := ""
+	if token != "" {
+		var err error
+		if c, err = decodeInboxCursor(token); err != nil {
+			return nil, err
+		}
+		noticeToken = c.noticeCursor()
+	}
+	backward := c != nil && c.Backward
+
+	// 两个来源各取一页，合并后截取limit条
+	page, err := i.notices.ListNotices(ctx, accountID, filter, noticeToken, limit)
+	if err != nil {
+		return nil, err
+	}
+	noticesMore := page.NextCursor != ""
+	if backward {
+		noticesMore = page.PrevCursor != ""
+	}
+	broadcasts, err := i.broadcasts.listVisible(ctx, accountID, audience.filter(filter.Type), filter.Status, c, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0, len(broadcasts))
+	for _, b := range broadcasts {
+		ids = append(ids, b.ID)
+	}
+	read, err := i.broadcasts.GetReadBroadcastIDs(ctx, accountID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*InboxItem, 0, len(page.Notices)+len(broadcasts))
+	for _, n := range page.Notices {
+		items = append(items, &InboxItem{Notice: n, Read: n.Status == constants.NOTIFICATION_STATUS_READ, CreatedAt: n.CreatedAt})
+	}
+	for _, b := range broadcasts {
+		items = append(items, &InboxItem{Broadcast: b, Read: read[b.ID], CreatedAt: b.CreatedAt})
+	}
+	// 按翻页方向排序，从游标处开始截取
+	sort.Slice(items, func(a, b int) bool {
+		if backward {
+			return items[a].position().before(items[b].position())
+		}
+		return items[b].position().before(items[a].position())
+	})
+	hasMore := noticesMore || len(items) > limit
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	if backward {
+		for a, b := 0, len(items)-1; a < b; a, b = a+1, b-1 {
+			items[a], items[b] = items[b], items[a]
+		}
+	}
+
+	result := &InboxPage{Items: items}
+	if len(items) == 0 {
+		return result, nil
+	}
+	first, last := items[0].position(), items[len(items)-1].position()
+	first.Backward = true
+	if backward {
+		if hasMore {
+			result.PrevCursor = first.encode()
+		}
+		result.NextCursor = last.encode()
+		return result, nil
+	}
+	if hasMore {
+		result.NextCursor = last.encode()
+	}
+	if c != nil {
+		result.PrevCursor = first.encode()
+	}
+	return result, nil
+}
+
+// UnreadCount 统计账号未读的个人通知与广播总数
+func (i *Inbox) UnreadCount(ctx context.Context, accountID int64, audience Audience) (int64, error) {
+	notices, err := i.notices.GetUnreadCount(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+	broadcasts, err := i.broadcasts.CountUnreadBroadcasts(ctx, accountID, audience.filter(nil))
+	if err != nil {
+		return 0, err
+	}
+	return notices + broadcasts, nil
+}
+
+// UnreadSummary 按通知类型和分类统计账号未读的个人通知与广播
+func (i *Inbox) UnreadSummary(ctx context.Context, accountID int64, audience Audience) (*UnreadSummary, error) {
+	summary, err := i.notices.GetUnreadSummary(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	broadcasts, err := i.broadcasts.GetUnreadBroadcastSummary(ctx, accountID, audience.filter(nil))
+	if err != nil {
+		return nil, err
+	}
+	for notifyType, count := range broadcasts.ByType {
+		summary.ByType[notifyType] += count
+	}
+	for category, count := range broadcasts.ByCategory {
+		summary.ByCategory[category] += count
+	}
+	summary.Total += broadcasts.Total
+	return summary, nil
+}
+
+// MarkAllRead 将账号的个人通知与可见广播全部标记为已读，notifyType不为nil时只处理该类型，返回更新数
+func (i *Inbox) MarkAllRead(ctx context.Context, accountID int64, audience Audience, notifyType *int8) (int64, error) {
+	notices, err := i.notices.MarkAllRead(ctx, accountID, notifyType)
+	if err != nil {
+		return 0, err
+	}
+	broadcasts, err := i.broadcasts.MarkAllBroadcastsRead(ctx, accountID, audience.filter(notifyType))
+	if err != nil {
+		return notices, err
+	}
+	return notices + broadcasts, nil
+}
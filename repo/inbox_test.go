@@ -0,0 +1,119 @@
+package repo_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereal3x/notice/repo"
+)
+
+// itemKey 列表项的标识，n表示个人通知，b表示广播
+func itemKey(item *repo.InboxItem) string {
+	if item.Broadcast != nil {
+		return fmt.Sprintf("b%s", item.Broadcast.Title)
+	}
+	return fmt.Sprintf("n%s", item.Notice.Title)
+}
+
+func TestInboxListPaginatesMergedStream(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	notices := repo.NewNoticeRepository(db)
+	broadcasts := repo.NewBroadcastRepository(db)
+	inbox := repo.NewInbox(notices, broadcasts)
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	// 个人通知与广播交错，并有同一时刻的个人通知与广播
+	for i, seq := range []int{0, 2, 4, 4, 7} {
+		n := &repo.Notification{AccountID: 1, Type: 1, Title: fmt.Sprint(i), Content: "c", CreatedAt: base.Add(time.Duration(seq) * time.Second)}
+		if err := notices.InsertNotice(ctx, n); err != nil {
+			t.Fatalf("InsertNotice: %v", err)
+		}
+	}
+	for i, seq := range []int{1, 4, 5, 6, 8} {
+		b := &repo.Broadcast{Type: 1, Title: fmt.Sprint(i), Content: "c", CreatedAt: base.Add(time.Duration(seq) * time.Second)}
+		if err := broadcasts.CreateBroadcast(ctx, b); err != nil {
+			t.Fatalf("CreateBroadcast: %v", err)
+		}
+	}
+	want := []string{"b4", "n4", "b3", "b2", "b1", "n3", "n2", "n1", "b0", "n0"}
+
+	var (
+		got   []string
+		pages []*repo.InboxPage
+		token string
+	)
+	for {
+		page, err := inbox.List(ctx, 1, repo.Audience{}, repo.NoticeFilter{}, token, 3)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		pages = append(pages, page)
+		for _, item := range page.Items {
+			got = append(got, itemKey(item))
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		token = page.NextCursor
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("forward = %v, want %v", got, want)
+	}
+
+	// 从最后一页向前翻页，得到相同的各页
+	token = pages[len(pages)-1].PrevCursor
+	for i := len(pages) - 2; i >= 0; i-- {
+		page, err := inbox.List(ctx, 1, repo.Audience{}, repo.NoticeFilter{}, token, 3)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		var keys, wantKeys []string
+		for _, item := range page.Items {
+			keys = append(keys, itemKey(item))
+		}
+		for _, item := range pages[i].Items {
+			wantKeys = append(wantKeys, itemKey(item))
+		}
+		if fmt.Sprint(keys) != fmt.Sprint(wantKeys) {
+			t.Fatalf("backward page %d = %v, want %v", i, keys, wantKeys)
+		}
+		if (i == 0) != (page.PrevCursor == "") {
+			t.Fatalf("backward page %d prev cursor = %q", i, page.PrevCursor)
+		}
+		token = page.PrevCursor
+	}
+}
+
+func TestInboxUnreadBroadcastsSinceAccountCreated(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	broadcasts := repo.NewBroadcastRepository(db)
+	inbox := repo.NewInbox(repo.NewNoticeRepository(db), broadcasts)
+	joined := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	for _, at := range []time.Time{joined.Add(-time.Minute), joined, joined.Add(time.Minute)} {
+		if err := broadcasts.CreateBroadcast(ctx, &repo.Broadcast{Type: 1, Title: "t", Content: "c", CreatedAt: at}); err != nil {
+			t.Fatalf("CreateBroadcast: %v", err)
+		}
+	}
+
+	if count, err := inbox.UnreadCount(ctx, 1, repo.Audience{Since: joined}); err != nil || count != 2 {
+		t.Fatalf("UnreadCount = %d, %v, want 2", count, err)
+	}
+	page, err := inbox.List(ctx, 1, repo.Audience{Since: joined}, repo.NoticeFilter{}, "", 10)
+	if err != nil || len(page.Items) != 2 {
+		t.Fatalf("List = %d items, %v, want 2", len(page.Items), err)
+	}
+	marked, err := inbox.MarkAllRead(ctx, 1, repo.Audience{Since: joined}, nil)
+	if err != nil || marked != 2 {
+		t.Fatalf("MarkAllRead = %d, %v, want 2", marked, err)
+	}
+	unread := int8(0)
+	page, err = inbox.List(ctx, 1, repo.Audience{}, repo.NoticeFilter{Status: &unread}, "", 10)
+	if err != nil || len(page.Items) != 1 {
+		t.Fatalf("List(unread) = %d items, %v, want the broadcast from before joining", len(page.Items), err)
+	}
+}
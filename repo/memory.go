@@ -0,0 +1,503 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereal3x/notice/constants"
+	"gorm.io/gorm"
+)
+
+// MemoryStore 纯内存的通知存储，语义与NoticeRepository一致，用于单元测试和本地开发
+// 数据只保存在进程内，重启后丢失
+type MemoryStore struct {
+	mu       sync.RWMutex
+	lastID   uint64
+	notices  map[uint64]*Notification // 包括已软删除的通知
+	archived []*ArchivedNotification
+	observer UnreadObserver
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{notices: make(map[uint64]*Notification)}
+}
+
+// WithUnreadObserver 设置未读数观察者
+func (s *MemoryStore) WithUnreadObserver(observer UnreadObserver) *MemoryStore {
+	s.observer = observer
+	return s
+}
+
+// clone 复制通知，避免调用方修改存储中的数据
+func (n *Notification) clone() *Notification {
+	c := *n
+	for _, t := range []**time.Time{&c.ReadAt, &c.ClickedAt, &c.DismissedAt, &c.ExpiresAt} {
+		if *t != nil {
+			v := **t
+			*t = &v
+		}
+	}
+	return &c
+}
+
+func (n *Notification) deleted() bool {
+	return n.DeletedAt.Valid
+}
+
+func (n *Notification) unread() bool {
+	return n.Status == constants.NOTIFICATION_STATUS_UNREAD && !n.expired()
+}
+
+func (n *Notification) markRead(now time.Time) {
+	n.Status = constants.NOTIFICATION_STATUS_READ
+	if n.ReadAt == nil {
+		n.ReadAt = &now
+	}
+	n.UpdatedAt = now
+}
+
+// insert 写入一条通知并回填ID和时间，调用方需持有写锁
+func (s *MemoryStore) insert(n *Notification, now time.Time) {
+	if n.ID == 0 {
+		n.ID = s.lastID + 1
+	}
+	if n.ID > s.lastID {
+		s.lastID = n.ID
+	}
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = now
+	}
+	if n.UpdatedAt.IsZero() {
+		n.UpdatedAt = now
+	}
+	s.notices[n.ID] = n.clone()
+}
+
+// checkDuplicate 检查待插入的通知是否与已有通知主键冲突，调用方需持有锁
+func (s *MemoryStore) checkDuplicate(notices []*Notification) error {
+	ids := make(map[uint64]bool, len(notices))
+	for _, n := range notices {
+		if n.ID == 0 {
+			continue
+		}
+		if _, exist := s.notices[n.ID]; exist || ids[n.ID] {
+			return fmt.Errorf("duplicate notification id %d: %w", n.ID, gorm.ErrDuplicatedKey)
+		}
+		ids[n.ID] = true
+	}
+	return nil
+}
+
+func (s *MemoryStore) InsertNotice(ctx context.Context, n *Notification) error {
+	_, err := s.InsertNotices(ctx, []*Notification{n})
+	return err
+}
+
+// InsertNotices 批量插入通知并回填ID，任一通知主键冲突时整批不插入
+func (s *MemoryStore) InsertNotices(ctx context.Context, notices []*Notification) (int64, error) {
+	if len(notices) == 0 {
+		return 0, nil
+	}
+	s.mu.Lock()
+	if err := s.checkDuplicate(notices); err != nil {
+		s.mu.Unlock()
+		return 0, err
+	}
+	now := time.Now()
+	for _, n := range notices {
+		s.insert(n, now)
+	}
+	s.mu.Unlock()
+
+	notifyInserted(ctx, s.observer, notices...)
+	return int64(len(notices)), nil
+}
+
+func (s *MemoryStore) GetNoticeByID(ctx context.Context, id uint64) (*Notification, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n, exist := s.notices[id]
+	if !exist || n.deleted() {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return n.clone(), nil
+}
+
+// find 返回满足match的未删除通知的副本，调用方需持有锁
+func (s *MemoryStore) find(match func(n *Notification) bool) []*Notification {
+	var notices []*Notification
+	for _, n := range s.notices {
+		if !n.deleted() && match(n) {
+			notices = append(notices, n.clone())
+		}
+	}
+	return notices
+}
+
+// sortNewestFirst 按(created_at, id)倒序排列
+func sortNewestFirst(notices []*Notification) {
+	sort.Slice(notices, func(i, j int) bool {
+		if !notices[i].CreatedAt.Equal(notices[j].CreatedAt) {
+			return notices[i].CreatedAt.After(notices[j].CreatedAt)
+		}
+		return notices[i].ID > notices[j].ID
+	})
+}
+
+// GetNoticesByAccountID 按LIMIT/OFFSET分页查询，通知较多时请使用ListNotices
+func (s *MemoryStore) GetNoticesByAccountID(ctx context.Context, accountID int64, status *int8, limit, offset int) ([]*Notification, error) {
+	s.mu.RLock()
+	notices := s.find(func(n *Notification) bool {
+		return n.AccountID == accountID && !n.expired() && (status == nil || n.Status == *status)
+	})
+	s.mu.RUnlock()
+
+	sortNewestFirst(notices)
+	if offset > 0 {
+		if offset >= len(notices) {
+			return nil, nil
+		}
+		notices = notices[offset:]
+	}
+	if limit >= 0 && len(notices) > limit {
+		notices = notices[:limit]
+	}
+	return notices, nil
+}
+
+// ListNotices 按(created_at, id)键集分页查询账号的通知，游标与NoticeRepository通用
+func (s *MemoryStore) ListNotices(ctx context.Context, accountID int64, filter NoticeFilter, token string, limit int) (*NoticePage, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var c *cursor
+	if token != "" {
+		var err error
+		if c, err = decodeCursor(token); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.RLock()
+	notices := s.find(func(n *Notification) bool {
+		if n.AccountID != accountID || n.expired() {
+			return false
+		}
+		if (filter.Status != nil && n.Status != *filter.Status) || (filter.Type != nil && n.Type != *filter.Type) {
+			return false
+		}
+		if c == nil {
+			return true
+		}
+		at := n.CreatedAt.UnixNano()
+		if c.Backward {
+			return at > c.CreatedAt || (at == c.CreatedAt && n.ID > c.ID)
+		}
+		return at < c.CreatedAt || (at == c.CreatedAt && n.ID < c.ID)
+	})
+	s.mu.RUnlock()
+
+	sortNewestFirst(notices)
+	if c != nil && c.Backward {
+		for i, j := 0, len(notices)-1; i < j; i, j = i+1, j-1 {
+			notices[i], notices[j] = notices[j], notices[i]
+		}
+	}
+	if len(notices) > limit+1 {
+		notices = notices[:limit+1]
+	}
+	return newNoticePage(c, notices, limit), nil
+}
+
+// UpdateNoticeStatus 更新通知状态，通知不存在或已删除时返回gorm.ErrRecordNotFound
+func (s *MemoryStore) UpdateNoticeStatus(ctx context.Context, id uint64, status int8) error {
+	s.mu.Lock()
+	n, exist := s.notices[id]
+	if !exist || n.deleted() {
+		s.mu.Unlock()
+		return gorm.ErrRecordNotFound
+	}
+	wasUnread := n.unread()
+	now := time.Now()
+	if status == constants.NOTIFICATION_STATUS_READ {
+		n.markRead(now)
+	} else {
+		n.Status = status
+		n.UpdatedAt = now
+	}
+	isUnread, accountID, notifyType := n.unread(), n.AccountID, n.Type
+	s.mu.Unlock()
+
+	if s.observer != nil && wasUnread != isUnread {
+		delta := int64(1)
+		if wasUnread {
+			delta = -1
+		}
+		s.observer.UnreadChanged(ctx, accountID, map[int8]int64{notifyType: delta})
+	}
+	return nil
+}
+
+// change 对账号下满足match的未删除通知执行apply，返回受影响行数，并通知观察者减少的未读数
+func (s *MemoryStore) change(ctx context.Context, accountID int64, match func(n *Notification) bool, apply func(n *Notification, now time.Time)) int64 {
+	var affected int64
+	counts := make(map[int8]int64)
+	now := time.Now()
+
+	s.mu.Lock()
+	for _, n := range s.notices {
+		if n.deleted() || n.AccountID != accountID || !match(n) {
+			continue
+		}
+		if n.unread() {
+			counts[n.Type]++
+		}
+		apply(n, now)
+		affected++
+	}
+	s.mu.Unlock()
+
+	notifyRemoved(ctx, s.observer, accountID, counts)
+	return affected
+}
+
+func idSet(ids []uint64) map[uint64]bool {
+	set := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// MarkReadByIDs 将账号下指定的未读通知标记为已读，不属于该账号的ID会被忽略，返回更新行数
+func (s *MemoryStore) MarkReadByIDs(ctx context.Context, accountID int64, ids []uint64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	set := idSet(ids)
+	return s.change(ctx, accountID, func(n *Notification) bool {
+		return set[n.ID] && n.Status == constants.NOTIFICATION_STATUS_UNREAD
+	}, (*Notification).markRead), nil
+}
+
+// MarkAllRead 将账号下所有未读通知标记为已读，notifyType不为nil时只处理该类型，返回更新行数
+func (s *MemoryStore) MarkAllRead(ctx context.Context, accountID int64, notifyType *int8) (int64, error) {
+	return s.change(ctx, accountID, func(n *Notification) bool {
+		return n.Status == constants.NOTIFICATION_STATUS_UNREAD && (notifyType == nil || n.Type == *notifyType)
+	}, (*Notification).markRead), nil
+}
+
+// MarkAllReadByCategory 将账号下某一分类的未读通知全部标记为已读，返回更新行数
+func (s *MemoryStore) MarkAllReadByCategory(ctx context.Context, accountID int64, category string) (int64, error) {
+	return s.change(ctx, accountID, func(n *Notification) bool {
+		return n.Status == constants.NOTIFICATION_STATUS_UNREAD && n.Category == category
+	}, (*Notification).markRead), nil
+}
+
+// DeleteNotice 用户删除单条通知（软删除），通知不存在或不属于该账号时返回false
+func (s *MemoryStore) DeleteNotice(ctx context.Context, accountID int64, id uint64) (bool, error) {
+	deleted, err := s.DeleteByIDs(ctx, accountID, []uint64{id})
+	return deleted > 0, err
+}
+
+// DeleteByIDs 删除账号下指定的通知（软删除），不属于该账号的ID会被忽略，返回删除行数
+func (s *MemoryStore) DeleteByIDs(ctx context.Context, accountID int64, ids []uint64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	set := idSet(ids)
+	return s.change(ctx, accountID, func(n *Notification) bool {
+		return set[n.ID]
+	}, func(n *Notification, now time.Time) {
+		n.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	}), nil
+}
+
+// unreadOf 返回账号下未过期的未读通知，调用方需持有锁
+func (s *MemoryStore) unreadOf(accountID int64) []*Notification {
+	return s.find(func(n *Notification) bool {
+		return n.AccountID == accountID && n.unread()
+	})
+}
+
+func (s *MemoryStore) GetUnreadCount(ctx context.Context, accountID int64) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int64(len(s.unreadOf(accountID))), nil
+}
+
+// GetUnreadCountsByType 按通知类型统计账号的未读数，没有未读的类型不出现在结果中
+func (s *MemoryStore) GetUnreadCountsByType(ctx context.Context, accountID int64) (map[int8]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	counts := make(map[int8]int64)
+	for _, n := range s.unreadOf(accountID) {
+		counts[n.Type]++
+	}
+	return counts, nil
+}
+
+// GetUnreadSummary 统计账号按通知类型和分类的未读数
+func (s *MemoryStore) GetUnreadSummary(ctx context.Context, accountID int64) (*UnreadSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	summary := newUnreadSummary()
+	for _, n := range s.unreadOf(accountID) {
+		summary.add(n.Type, n.Category, 1)
+	}
+	return summary, nil
+}
+
+// CollapseNotice 按折叠键写入通知：账号下存在同一折叠键的未读通知时用n的内容覆盖最新的一条并返回true，否则插入n，折叠键为空时直接插入
+func (s *MemoryStore) CollapseNotice(ctx context.Context, n *Notification, merge func(prev *Notification)) (bool, error) {
+	if n.CollapseKey == "" {
+		return false, s.InsertNotice(ctx, n)
+	}
+	s.mu.Lock()
+	var prev *Notification
+	for _, stored := range s.notices {
+		if stored.AccountID == n.AccountID && stored.CollapseKey == n.CollapseKey && !stored.deleted() && stored.unread() &&
+			(prev == nil || stored.ID > prev.ID) {
+			prev = stored
+		}
+	}
+	if prev == nil {
+		if err := s.checkDuplicate([]*Notification{n}); err != nil {
+			s.mu.Unlock()
+			return false, err
+		}
+		s.insert(n, time.Now())
+		s.mu.Unlock()
+		notifyInserted(ctx, s.observer, n)
+		return false, nil
+	}
+	defer s.mu.Unlock()
+
+	merge(prev.clone())
+	prev.Title = n.Title
+	prev.Content = n.Content
+	prev.Locale = n.Locale
+	prev.ExtData = n.ExtData
+	prev.ExpiresAt = n.clone().ExpiresAt
+	prev.CreatedAt = n.CreatedAt
+	prev.UpdatedAt = n.UpdatedAt
+	n.ID = prev.ID
+	return true, nil
+}
+
+// RecordEngagement 记录用户对通知的互动，每种互动只保留首次时间
+// 点击和忽略同时视为已读；通知不存在或不属于该账号时返回false
+func (s *MemoryStore) RecordEngagement(ctx context.Context, accountID int64, id uint64, action EngagementAction) (bool, error) {
+	if _, exist := engagementColumns[action]; !exist {
+		return false, ErrUnknownEngagement
+	}
+	s.mu.RLock()
+	n, exist := s.notices[id]
+	found := exist && !n.deleted() && n.AccountID == accountID
+	s.mu.RUnlock()
+	if !found {
+		return false, nil
+	}
+
+	if _, err := s.MarkReadByIDs(ctx, accountID, []uint64{id}); err != nil {
+		return false, err
+	}
+	s.change(ctx, accountID, func(n *Notification) bool {
+		return n.ID == id
+	}, func(n *Notification, now time.Time) {
+		switch action {
+		case EngagementClick:
+			if n.ClickedAt == nil {
+				n.ClickedAt = &now
+			}
+		case EngagementDismiss:
+			if n.DismissedAt == nil {
+				n.DismissedAt = &now
+			}
+		}
+	})
+	return true, nil
+}
+
+// GetEngagementReport 统计[from, to)期间创建的通知按类型和活动的送达、阅读、点击情况
+// 用户已删除的通知同样计入
+func (s *MemoryStore) GetEngagementReport(ctx context.Context, from, to time.Time) ([]*EngagementStat, error) {
+	type key struct {
+		notifyType   int8
+		activityName string
+	}
+	groups := make(map[key]*EngagementStat)
+
+	s.mu.RLock()
+	for _, n := range s.notices {
+		if n.CreatedAt.Before(from) || !n.CreatedAt.Before(to) {
+			continue
+		}
+		k := key{n.Type, n.ActivityName}
+		stat, exist := groups[k]
+		if !exist {
+			stat = &EngagementStat{Type: n.Type, ActivityName: n.ActivityName}
+			groups[k] = stat
+		}
+		stat.Delivered++
+		if n.ReadAt != nil {
+			stat.Read++
+		}
+		if n.ClickedAt != nil {
+			stat.Clicked++
+		}
+		if n.DismissedAt != nil {
+			stat.Dismissed++
+		}
+	}
+	s.mu.RUnlock()
+
+	stats := make([]*EngagementStat, 0, len(groups))
+	for _, stat := range groups {
+		if stat.Delivered > 0 {
+			stat.ReadRate = float64(stat.Read) / float64(stat.Delivered)
+		}
+		if stat.Read > 0 {
+			stat.ClickRate = float64(stat.Clicked) / float64(stat.Read)
+		}
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Type != stats[j].Type {
+			return stats[i].Type < stats[j].Type
+		}
+		return stats[i].ActivityName < stats[j].ActivityName
+	})
+	return stats, nil
+}
+
+// ArchiveNotices 将指定类型中创建时间早于before的通知（包括已删除的）移入归档，返回归档行数
+// 每次最多处理limit行，调用方循环调用直到返回0
+func (s *MemoryStore) ArchiveNotices(ctx context.Context, notifyType int8, before time.Time, limit int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var notices []*Notification
+	for _, n := range s.notices {
+		if n.Type == notifyType && n.CreatedAt.Before(before) {
+			notices = append(notices, n)
+		}
+	}
+	sort.Slice(notices, func(i, j int) bool {
+		return notices[i].ID < notices[j].ID
+	})
+	if limit >= 0 && len(notices) > limit {
+		notices = notices[:limit]
+	}
+
+	now := time.Now()
+	for _, n := range notices {
+		s.archived = append(s.archived, &ArchivedNotification{Notification: *n, ArchivedAt: now})
+		delete(s.notices, n.ID)
+	}
+	notifyArchived(ctx, s.observer, notices)
+	return int64(len(notices)), nil
+}
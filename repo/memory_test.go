@@ -0,0 +1,14 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/repo/storetest"
+)
+
+func TestMemoryStoreConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, observer repo.UnreadObserver) repo.NotificationStore {
+		return repo.NewMemoryStore().WithUnreadObserver(observer)
+	})
+}
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationPreference 用户通知偏好，按账号+通知类型+渠道订阅
+type NotificationPreference struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	AccountID int64     `gorm:"column:account_id;not null;uniqueIndex:uk_account_type_channel;comment:用户账号ID" json:"account_id"`
+	Type      int8      `gorm:"column:type;not null;uniqueIndex:uk_account_type_channel;comment:通知类型: 1-稿件审核 2-认证审核 3-奖励发放 4-汇总" json:"type"`
+	Channel   string    `gorm:"column:channel;type:varchar(32);not null;uniqueIndex:uk_account_type_channel;comment:投递渠道" json:"channel"`
+	Enabled   bool      `gorm:"column:enabled;not null;comment:是否接收" json:"enabled"`
+	Reroute   string    `gorm:"column:reroute;type:varchar(32);not null;default:'';comment:改投渠道，为空表示不改投" json:"reroute"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (NotificationPreference) TableName() string {
+	return "tbl_notification_preference"
+}
+
+type PreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewPreferenceRepository(db *gorm.DB) *PreferenceRepository {
+	return &PreferenceRepository{db: db}
+}
+
+func (r *PreferenceRepository) GetPreferencesByAccountID(ctx context.Context, accountID int64) ([]*NotificationPreference, error) {
+	var prefs []*NotificationPreference
+	err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("type, channel").Find(&prefs).Error
+	if err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// GetPreference 查询单条偏好，不存在时返回nil
+func (r *PreferenceRepository) GetPreference(ctx context.Context, accountID int64, notifyType int8, channel string) (*NotificationPreference, error) {
+	var p NotificationPreference
+	err := r.db.WithContext(ctx).
+		Where("account_id = ? AND type = ? AND channel = ?", accountID, notifyType, channel).
+		First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpsertPreference 按账号+类型+渠道插入或更新偏好
+// Enabled不设置gorm默认值，否则首次插入时false会被当作零值省略，退订被列默认值覆盖
+func (r *PreferenceRepository) UpsertPreference(ctx context.Context, p *NotificationPreference) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}, {Name: "type"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "reroute", "updated_at"}),
+	}).Create(p).Error
+}
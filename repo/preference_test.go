@@ -0,0 +1,34 @@
+package repo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereal3x/notice/repo"
+)
+
+func TestUpsertPreferenceOptOutOnFirstInsert(t *testing.T) {
+	ctx := context.Background()
+	prefs := repo.NewPreferenceRepository(newSQLiteDB(t))
+
+	err := prefs.UpsertPreference(ctx, &repo.NotificationPreference{AccountID: 1, Type: 1, Channel: "sms", Enabled: false})
+	if err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	p, err := prefs.GetPreference(ctx, 1, 1, "sms")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if p == nil || p.Enabled {
+		t.Fatalf("preference = %+v, want enabled=false", p)
+	}
+
+	// 再次订阅走更新分支
+	err = prefs.UpsertPreference(ctx, &repo.NotificationPreference{AccountID: 1, Type: 1, Channel: "sms", Enabled: true})
+	if err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	if p, _ = prefs.GetPreference(ctx, 1, 1, "sms"); p == nil || !p.Enabled {
+		t.Fatalf("preference = %+v, want enabled=true", p)
+	}
+}
@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QuietHours 用户免打扰时段，StartMinute/EndMinute为当天分钟数，Start大于End表示跨零点
+type QuietHours struct {
+	ID          uint64    `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	AccountID   int64     `gorm:"column:account_id;not null;uniqueIndex:uk_account;comment:用户账号ID" json:"account_id"`
+	StartMinute int       `gorm:"column:start_minute;not null;comment:开始时间(当天分钟数)" json:"start_minute"`
+	EndMinute   int       `gorm:"column:end_minute;not null;comment:结束时间(当天分钟数)" json:"end_minute"`
+	Timezone    string    `gorm:"column:timezone;type:varchar(64);not null;default:'Asia/Shanghai';comment:时区" json:"timezone"`
+	Enabled     bool      `gorm:"column:enabled;not null;comment:是否启用" json:"enabled"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (QuietHours) TableName() string {
+	return "tbl_notification_quiet_hours"
+}
+
+type QuietHoursRepository struct {
+	db *gorm.DB
+}
+
+func NewQuietHoursRepository(db *gorm.DB) *QuietHoursRepository {
+	return &QuietHoursRepository{db: db}
+}
+
+// GetQuietHours 查询账号免打扰设置，不存在时返回nil
+func (r *QuietHoursRepository) GetQuietHours(ctx context.Context, accountID int64) (*QuietHours, error) {
+	var q QuietHours
+	err := r.db.WithContext(ctx).Where("account_id = ?", accountID).First(&q).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// UpsertQuietHours 插入或更新账号免打扰设置
+// Enabled不设置gorm默认值，否则首次插入时false会被当作零值省略
+func (r *QuietHoursRepository) UpsertQuietHours(ctx context.Context, q *QuietHours) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"start_minute", "end_minute", "timezone", "enabled", "updated_at"}),
+	}).Create(q).Error
+}
@@ -0,0 +1,25 @@
+package repo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereal3x/notice/repo"
+)
+
+func TestUpsertQuietHoursDisabledOnFirstInsert(t *testing.T) {
+	ctx := context.Background()
+	quietHours := repo.NewQuietHoursRepository(newSQLiteDB(t))
+
+	err := quietHours.UpsertQuietHours(ctx, &repo.QuietHours{AccountID: 1, StartMinute: 22 * 60, EndMinute: 8 * 60, Timezone: "Asia/Shanghai", Enabled: false})
+	if err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	q, err := quietHours.GetQuietHours(ctx, 1)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if q == nil || q.Enabled {
+		t.Fatalf("quiet hours = %+v, want enabled=false", q)
+	}
+}
@@ -2,21 +2,33 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/ethereal3x/notice/constants"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Notification struct {
-	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
-	AccountID int64     `gorm:"column:account_id;not null;comment:用户账号ID" json:"account_id"`
-	Type      int8      `gorm:"column:type;not null;comment:通知类型: 1-稿件审核 2-认证审核 3-奖励发放" json:"type"`
-	Title     string    `gorm:"column:title;type:varchar(255);not null;comment:通知标题" json:"title"`
-	Content   string    `gorm:"column:content;type:text;not null;comment:通知内容" json:"content"`
-	Status    int8      `gorm:"column:status;not null;default:0;comment:状态: 0-未读 1-已读" json:"status"`
-	ExtData   string    `gorm:"column:ext_data;type:text;comment:扩展数据(JSON格式)" json:"ext_data"`
-	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
-	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+	ID           uint64         `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	AccountID    int64          `gorm:"column:account_id;not null;comment:用户账号ID" json:"account_id"`
+	Type         int8           `gorm:"column:type;not null;comment:通知类型: 1-稿件审核 2-认证审核 3-奖励发放 4-汇总" json:"type"`
+	Category     string         `gorm:"column:category;type:varchar(32);not null;default:'';comment:通知分类: audit-审核 reward-奖励 system-系统" json:"category"`
+	Title        string         `gorm:"column:title;type:varchar(255);not null;comment:通知标题" json:"title"`
+	Content      string         `gorm:"column:content;type:text;not null;comment:通知内容" json:"content"`
+	Status       int8           `gorm:"column:status;not null;default:0;comment:状态: 0-未读 1-已读" json:"status"`
+	Locale       string         `gorm:"column:locale;type:varchar(16);not null;default:'';comment:渲染使用的语言" json:"locale"`
+	CollapseKey  string         `gorm:"column:collapse_key;type:varchar(128);not null;default:'';comment:折叠键，同键的未读通知会被新通知覆盖" json:"collapse_key"`
+	ActivityName string         `gorm:"column:activity_name;type:varchar(128);not null;default:'';comment:关联的活动名称，用于按活动统计" json:"activity_name"`
+	ExtData      string         `gorm:"column:ext_data;type:text;comment:扩展数据(JSON格式)" json:"ext_data"`
+	CreatedAt    time.Time      `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+	ReadAt       *time.Time     `gorm:"column:read_at;comment:首次阅读时间" json:"read_at,omitempty"`
+	ClickedAt    *time.Time     `gorm:"column:clicked_at;comment:首次点击时间" json:"clicked_at,omitempty"`
+	DismissedAt  *time.Time     `gorm:"column:dismissed_at;comment:首次忽略时间" json:"dismissed_at,omitempty"`
+	ExpiresAt    *time.Time     `gorm:"column:expires_at;comment:过期时间，为空表示不过期" json:"expires_at,omitempty"`
+	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at;comment:删除时间" json:"-"`
 }
 
 // TableName 指定表名
@@ -24,16 +36,52 @@ func (Notification) TableName() string {
 	return "tbl_notification"
 }
 
+// DefaultInsertChunkSize 批量插入时每条INSERT语句的默认行数
+const DefaultInsertChunkSize = 100
+
 type NoticeRepository struct {
-	db *gorm.DB
+	db              *gorm.DB
+	insertChunkSize int
+	observer        UnreadObserver
 }
 
 func NewNoticeRepository(db *gorm.DB) *NoticeRepository {
-	return &NoticeRepository{db: db}
+	return &NoticeRepository{db: db, insertChunkSize: DefaultInsertChunkSize}
+}
+
+// WithInsertChunkSize 设置批量插入时每条INSERT语句的行数
+func (r *NoticeRepository) WithInsertChunkSize(size int) *NoticeRepository {
+	if size > 0 {
+		r.insertChunkSize = size
+	}
+	return r
+}
+
+// notExpired 排除已过期的通知
+func notExpired(db *gorm.DB) *gorm.DB {
+	return db.Where("expires_at IS NULL OR expires_at > ?", time.Now())
 }
 
 func (r *NoticeRepository) InsertNotice(ctx context.Context, n *Notification) error {
-	return r.db.WithContext(ctx).Create(n).Error
+	if err := r.db.WithContext(ctx).Create(n).Error; err != nil {
+		return err
+	}
+	notifyInserted(ctx, r.observer, n)
+	return nil
+}
+
+// InsertNotices 按chunk大小分多条INSERT批量插入通知，插入后回填各通知的ID，返回插入行数
+// 所有chunk在同一事务中执行，任一失败时整批回滚
+func (r *NoticeRepository) InsertNotices(ctx context.Context, notices []*Notification) (int64, error) {
+	if len(notices) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).CreateInBatches(&notices, r.insertChunkSize)
+	if result.Error != nil {
+		return result.RowsAffected, result.Error
+	}
+	notifyInserted(ctx, r.observer, notices...)
+	return result.RowsAffected, nil
 }
 
 func (r *NoticeRepository) GetNoticeByID(ctx context.Context, id uint64) (*Notification, error) {
@@ -45,9 +93,10 @@ func (r *NoticeRepository) GetNoticeByID(ctx context.Context, id uint64) (*Notif
 	return &n, nil
 }
 
+// GetNoticesByAccountID 按LIMIT/OFFSET分页查询，通知较多时请使用ListNotices
 func (r *NoticeRepository) GetNoticesByAccountID(ctx context.Context, accountID int64, status *int8, limit, offset int) ([]*Notification, error) {
 	var notices []*Notification
-	query := r.db.WithContext(ctx).Where("account_id = ?", accountID)
+	query := r.db.WithContext(ctx).Where("account_id = ?", accountID).Scopes(notExpired)
 
 	if status != nil {
 		query = query.Where("status = ?", *status)
@@ -60,12 +109,165 @@ func (r *NoticeRepository) GetNoticesByAccountID(ctx context.Context, accountID
 	return notices, nil
 }
 
+// statusUpdates 更新状态的字段，标记已读时记录首次阅读时间
+func statusUpdates(status int8) map[string]interface{} {
+	updates := map[string]interface{}{"status": status}
+	if status == constants.NOTIFICATION_STATUS_READ {
+		updates["read_at"] = gorm.Expr("COALESCE(read_at, ?)", time.Now())
+	}
+	return updates
+}
+
+// UpdateNoticeStatus 更新通知状态，通知不存在或已删除时返回gorm.ErrRecordNotFound
 func (r *NoticeRepository) UpdateNoticeStatus(ctx context.Context, id uint64, status int8) error {
-	return r.db.WithContext(ctx).Model(&Notification{}).Where("id = ?", id).Update("status", status).Error
+	if r.observer == nil {
+		result := r.db.WithContext(ctx).Model(&Notification{}).Where("id = ?", id).Updates(statusUpdates(status))
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+		return r.checkExists(ctx, id)
+	}
+
+	// 只有状态在未读与已读之间变化时才影响未读数
+	prev, err := r.GetNoticeByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(&Notification{}).
+		Where("id = ? AND status = ?", id, prev.Status).
+		Updates(statusUpdates(status))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// 查询之后被并发删除，或状态已被并发修改
+		return r.checkExists(ctx, id)
+	}
+	if prev.Status == status || prev.expired() {
+		return nil
+	}
+	delta := int64(1)
+	if status != constants.NOTIFICATION_STATUS_UNREAD {
+		delta = -1
+	}
+	r.observer.UnreadChanged(ctx, prev.AccountID, map[int8]int64{prev.Type: delta})
+	return nil
+}
+
+// checkExists 更新未影响任何行时确认通知是否存在，MySQL在值没有变化时同样返回0行
+func (r *NoticeRepository) checkExists(ctx context.Context, id uint64) error {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&Notification{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MarkReadByIDs 将账号下指定的未读通知标记为已读，不属于该账号的ID会被忽略，返回更新行数
+func (r *NoticeRepository) MarkReadByIDs(ctx context.Context, accountID int64, ids []uint64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return r.changeUnread(ctx, accountID, func(db *gorm.DB) *gorm.DB {
+		return db.Where("account_id = ? AND id IN ? AND status = ?", accountID, ids, constants.NOTIFICATION_STATUS_UNREAD)
+	}, func(tx *gorm.DB) *gorm.DB {
+		return tx.Updates(statusUpdates(constants.NOTIFICATION_STATUS_READ))
+	})
+}
+
+// MarkAllRead 将账号下所有未读通知标记为已读，notifyType不为nil时只处理该类型，返回更新行数
+func (r *NoticeRepository) MarkAllRead(ctx context.Context, accountID int64, notifyType *int8) (int64, error) {
+	return r.changeUnread(ctx, accountID, func(db *gorm.DB) *gorm.DB {
+		db = db.Where("account_id = ? AND status = ?", accountID, constants.NOTIFICATION_STATUS_UNREAD)
+		if notifyType != nil {
+			db = db.Where("type = ?", *notifyType)
+		}
+		return db
+	}, func(tx *gorm.DB) *gorm.DB {
+		return tx.Updates(statusUpdates(constants.NOTIFICATION_STATUS_READ))
+	})
+}
+
+// DeleteNotice 用户删除单条通知（软删除），通知不存在或不属于该账号时返回false
+func (r *NoticeRepository) DeleteNotice(ctx context.Context, accountID int64, id uint64) (bool, error) {
+	deleted, err := r.DeleteByIDs(ctx, accountID, []uint64{id})
+	return deleted > 0, err
+}
+
+// DeleteByIDs 删除账号下指定的通知（软删除），不属于该账号的ID会被忽略，返回删除行数
+func (r *NoticeRepository) DeleteByIDs(ctx context.Context, accountID int64, ids []uint64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return r.changeUnread(ctx, accountID, func(db *gorm.DB) *gorm.DB {
+		return db.Where("account_id = ? AND id IN ?", accountID, ids)
+	}, func(tx *gorm.DB) *gorm.DB {
+		return tx.Delete(&Notification{})
+	})
 }
 
 func (r *NoticeRepository) GetUnreadCount(ctx context.Context, accountID int64) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&Notification{}).Where("account_id = ? AND status = ?", accountID, 0).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&Notification{}).
+		Where("account_id = ? AND status = ?", accountID, constants.NOTIFICATION_STATUS_UNREAD).
+		Scopes(notExpired).
+		Count(&count).Error
 	return count, err
 }
+
+// CollapseNotice 按折叠键写入通知：账号下存在同一折叠键的未读通知时用n的内容覆盖最新的一条并返回true，否则插入n
+// merge在覆盖前以被覆盖的通知调用，用于将历史合并到n.ExtData；覆盖后n.ID为被覆盖通知的ID。
+// 折叠键为空时直接插入n，不与其他通知折叠
+//
+// 查找、覆盖和插入在同一事务中完成，查找时对匹配的行及(account_id, collapse_key)索引区间加锁，
+// 并发写入同一折叠键时后到的事务等待或因死锁失败后由分发器重试，不会产生两条未读通知。
+// 依赖InnoDB默认的REPEATABLE READ隔离级别提供的间隙锁；SQLite的写事务本身是串行的
+func (r *NoticeRepository) CollapseNotice(ctx context.Context, n *Notification, merge func(prev *Notification)) (bool, error) {
+	if n.CollapseKey == "" {
+		return false, r.InsertNotice(ctx, n)
+	}
+	var superseded bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var prev Notification
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("account_id = ? AND collapse_key = ? AND status = ?", n.AccountID, n.CollapseKey, constants.NOTIFICATION_STATUS_UNREAD).
+			Scopes(notExpired).
+			Order("id DESC").
+			First(&prev).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(n).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		merge(&prev)
+		err = tx.Model(&Notification{}).
+			Where("id = ?", prev.ID).
+			Updates(map[string]interface{}{
+				"title":      n.Title,
+				"content":    n.Content,
+				"locale":     n.Locale,
+				"ext_data":   n.ExtData,
+				"expires_at": n.ExpiresAt,
+				"created_at": n.CreatedAt,
+				"updated_at": n.UpdatedAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+		n.ID = prev.ID
+		superseded = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if !superseded {
+		notifyInserted(ctx, r.observer, n)
+	}
+	return superseded, nil
+}
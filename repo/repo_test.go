@@ -0,0 +1,112 @@
+package repo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereal3x/notice/repo"
+)
+
+// insertNotices 为账号插入指定类型的未读通知
+func insertNotices(t *testing.T, r *repo.NoticeRepository, accountID int64, types ...int8) []*repo.Notification {
+	t.Helper()
+	notices := make([]*repo.Notification, 0, len(types))
+	for _, notifyType := range types {
+		notices = append(notices, &repo.Notification{AccountID: accountID, Type: notifyType, Title: "title", Content: "content"})
+	}
+	if _, err := r.InsertNotices(context.Background(), notices); err != nil {
+		t.Fatalf("InsertNotices: %v", err)
+	}
+	return notices
+}
+
+func TestInsertNoticesChunked(t *testing.T) {
+	ctx := context.Background()
+	r := repo.NewNoticeRepository(newSQLiteDB(t)).WithInsertChunkSize(2)
+
+	notices := make([]*repo.Notification, 5)
+	for i := range notices {
+		notices[i] = &repo.Notification{AccountID: 1, Type: 3, Title: "title", Content: string(rune('a' + i))}
+	}
+	affected, err := r.InsertNotices(ctx, notices)
+	if err != nil || affected != 5 {
+		t.Fatalf("InsertNotices = %d, %v, want 5", affected, err)
+	}
+
+	seen := make(map[uint64]bool)
+	for _, n := range notices {
+		if n.ID == 0 || seen[n.ID] {
+			t.Fatalf("notice id %d not back-filled or duplicated", n.ID)
+		}
+		seen[n.ID] = true
+		got, err := r.GetNoticeByID(ctx, n.ID)
+		if err != nil || got.Content != n.Content {
+			t.Fatalf("GetNoticeByID(%d) = %+v, %v, want content %q", n.ID, got, err, n.Content)
+		}
+	}
+}
+
+func TestMarkReadByIDsScopedToAccount(t *testing.T) {
+	ctx := context.Background()
+	r := repo.NewNoticeRepository(newSQLiteDB(t))
+	mine := insertNotices(t, r, 1, 1, 1)
+	other := insertNotices(t, r, 2, 1)
+
+	affected, err := r.MarkReadByIDs(ctx, 1, []uint64{mine[0].ID, other[0].ID})
+	if err != nil || affected != 1 {
+		t.Fatalf("MarkReadByIDs = %d, %v, want 1", affected, err)
+	}
+	// 已读的通知不再计入
+	if affected, _ := r.MarkReadByIDs(ctx, 1, []uint64{mine[0].ID}); affected != 0 {
+		t.Fatalf("MarkReadByIDs(read) = %d, want 0", affected)
+	}
+	if count, _ := r.GetUnreadCount(ctx, 1); count != 1 {
+		t.Fatalf("unread(1) = %d, want 1", count)
+	}
+	if count, _ := r.GetUnreadCount(ctx, 2); count != 1 {
+		t.Fatalf("unread(2) = %d, want 1", count)
+	}
+}
+
+func TestMarkAllReadByType(t *testing.T) {
+	ctx := context.Background()
+	r := repo.NewNoticeRepository(newSQLiteDB(t))
+	insertNotices(t, r, 1, 1, 1, 3)
+	insertNotices(t, r, 2, 1)
+
+	notifyType := int8(1)
+	affected, err := r.MarkAllRead(ctx, 1, &notifyType)
+	if err != nil || affected != 2 {
+		t.Fatalf("MarkAllRead(type 1) = %d, %v, want 2", affected, err)
+	}
+	counts, _ := r.GetUnreadCountsByType(ctx, 1)
+	if counts[1] != 0 || counts[3] != 1 {
+		t.Fatalf("unread by type = %v, want only type 3", counts)
+	}
+
+	affected, err = r.MarkAllRead(ctx, 1, nil)
+	if err != nil || affected != 1 {
+		t.Fatalf("MarkAllRead = %d, %v, want 1", affected, err)
+	}
+	if count, _ := r.GetUnreadCount(ctx, 2); count != 1 {
+		t.Fatalf("unread(2) = %d, want 1", count)
+	}
+}
+
+func TestDeleteByIDsScopedToAccount(t *testing.T) {
+	ctx := context.Background()
+	r := repo.NewNoticeRepository(newSQLiteDB(t))
+	mine := insertNotices(t, r, 1, 1, 1)
+	other := insertNotices(t, r, 2, 1)
+
+	affected, err := r.DeleteByIDs(ctx, 1, []uint64{mine[0].ID, mine[1].ID, other[0].ID})
+	if err != nil || affected != 2 {
+		t.Fatalf("DeleteByIDs = %d, %v, want 2", affected, err)
+	}
+	if affected, _ := r.DeleteByIDs(ctx, 1, []uint64{mine[0].ID}); affected != 0 {
+		t.Fatalf("DeleteByIDs(deleted) = %d, want 0", affected)
+	}
+	if _, err := r.GetNoticeByID(ctx, other[0].ID); err != nil {
+		t.Fatalf("other account's notice deleted: %v", err)
+	}
+}
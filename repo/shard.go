@@ -0,0 +1,378 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidShards 分片数不合法或与分片策略不一致
+var ErrInvalidShards = errors.New("invalid shard configuration")
+
+// Sharder 将账号映射到分片下标，返回值需在[0, Shards())范围内
+type Sharder interface {
+	Shard(accountID int64) int
+	// Shards 返回分片数
+	Shards() int
+}
+
+// ModuloSharder 按账号ID取模分片，分片数变化时大部分账号需要迁移
+type ModuloSharder struct {
+	shards int
+}
+
+// NewModuloSharder 创建取模分片，shards需大于0
+func NewModuloSharder(shards int) (*ModuloSharder, error) {
+	if shards <= 0 {
+		return nil, fmt.Errorf("%w: %d shards", ErrInvalidShards, shards)
+	}
+	return &ModuloSharder{shards: shards}, nil
+}
+
+func (s *ModuloSharder) Shards() int {
+	return s.shards
+}
+
+func (s *ModuloSharder) Shard(accountID int64) int {
+	shard := accountID % int64(s.shards)
+	if shard < 0 {
+		shard += int64(s.shards)
+	}
+	return int(shard)
+}
+
+// DefaultVirtualNodes 一致性哈希中每个分片的默认虚拟节点数
+const DefaultVirtualNodes = 160
+
+// ConsistentHashSharder 一致性哈希分片，增加分片时只有约1/N的账号需要迁移
+type ConsistentHashSharder struct {
+	shards int
+	ring   []uint32
+	owners map[uint32]int
+}
+
+// NewConsistentHashSharder 创建一致性哈希分片，shards需大于0，virtualNodes越多账号分布越均匀
+func NewConsistentHashSharder(shards, virtualNodes int) (*ConsistentHashSharder, error) {
+	if shards <= 0 {
+		return nil, fmt.Errorf("%w: %d shards", ErrInvalidShards, shards)
+	}
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+	s := &ConsistentHashSharder{shards: shards, owners: make(map[uint32]int, shards*virtualNodes)}
+	for shard := 0; shard < shards; shard++ {
+		for i := 0; i < virtualNodes; i++ {
+			point := hash32(fmt.Sprintf("shard-%d#%d", shard, i))
+			// 哈希冲突时保留编号较小的分片
+			if _, exist := s.owners[point]; exist {
+				continue
+			}
+			s.owners[point] = shard
+			s.ring = append(s.ring, point)
+		}
+	}
+	sort.Slice(s.ring, func(i, j int) bool {
+		return s.ring[i] < s.ring[j]
+	})
+	return s, nil
+}
+
+func (s *ConsistentHashSharder) Shards() int {
+	return s.shards
+}
+
+func (s *ConsistentHashSharder) Shard(accountID int64) int {
+	point := hash32(strconv.FormatInt(accountID, 10))
+	i := sort.Search(len(s.ring), func(i int) bool {
+		return s.ring[i] >= point
+	})
+	if i == len(s.ring) {
+		i = 0
+	}
+	return s.owners[s.ring[i]]
+}
+
+// hash32 计算环上的位置，FNV对相近的短字符串分布不均，再经过一次混淆
+func hash32(key string) uint32 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return uint32(x >> 32)
+}
+
+// idEpoch 通知ID中时间戳的起点
+var idEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+// idGenerator 生成跨分片唯一且随时间递增的通知ID
+// 格式为 41位毫秒时间戳 | 10位节点号 | 12位序号，多个服务实例需使用不同的节点号
+type idGenerator struct {
+	mu     sync.Mutex
+	node   int64
+	lastMs int64
+	seq    int64
+}
+
+func (g *idGenerator) next() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now().UnixMilli() - idEpoch
+	// 时钟回拨时沿用上次的时间戳，保证ID递增
+	if now < g.lastMs {
+		now = g.lastMs
+	}
+	if now == g.lastMs {
+		g.seq = (g.seq + 1) & 0xfff
+		if g.seq == 0 {
+			// 同一毫秒序号用完，借用下一毫秒
+			now++
+		}
+	} else {
+		g.seq = 0
+	}
+	g.lastMs = now
+	return uint64(now<<22 | g.node<<12 | g.seq)
+}
+
+// ShardedStore 按账号ID将通知分布到多个存储，通常每个分片是一个独立数据库上的NoticeRepository
+// 账号相关的操作路由到账号所在的分片；只有通知ID的操作和管理查询并发访问所有分片后合并结果
+//
+// 各分片的自增ID会重复，因此由ShardedStore在插入前分配全局唯一的ID
+type ShardedStore struct {
+	shards  []NotificationStore
+	sharder Sharder
+	ids     *idGenerator
+}
+
+// NewShardedStore 创建分片存储，sharder返回的下标对应shards中的位置，分片数需与sharder一致
+func NewShardedStore(sharder Sharder, shards ...NotificationStore) (*ShardedStore, error) {
+	if len(shards) == 0 || sharder.Shards() != len(shards) {
+		return nil, fmt.Errorf("%w: sharder expects %d shards, got %d", ErrInvalidShards, sharder.Shards(), len(shards))
+	}
+	return &ShardedStore{shards: shards, sharder: sharder, ids: &idGenerator{}}, nil
+}
+
+// WithNodeID 设置生成通知ID使用的节点号(0-1023)，多个服务实例写入同一组分片时必须互不相同
+func (s *ShardedStore) WithNodeID(node int64) *ShardedStore {
+	s.ids.node = node & 0x3ff
+	return s
+}
+
+// ShardFor 返回账号所在的分片
+func (s *ShardedStore) ShardFor(accountID int64) NotificationStore {
+	return s.shards[s.sharder.Shard(accountID)]
+}
+
+// ForEachShard 并发地对每个分片执行fn，用于跨分片的管理查询，返回所有分片的错误
+func (s *ShardedStore) ForEachShard(ctx context.Context, fn func(ctx context.Context, shard int, store NotificationStore) error) error {
+	errs := make([]error, len(s.shards))
+	var wg sync.WaitGroup
+	for i, store := range s.shards {
+		wg.Add(1)
+		go func(i int, store NotificationStore) {
+			defer wg.Done()
+			if err := fn(ctx, i, store); err != nil {
+				errs[i] = fmt.Errorf("shard %d: %w", i, err)
+			}
+		}(i, store)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (s *ShardedStore) InsertNotice(ctx context.Context, n *Notification) error {
+	if n.ID == 0 {
+		n.ID = s.ids.next()
+	}
+	return s.ShardFor(n.AccountID).InsertNotice(ctx, n)
+}
+
+// InsertNotices 按分片分组批量插入，每个分片内整批成功或失败，分片之间不保证原子性
+// 某个分片失败时返回已插入的行数和错误
+func (s *ShardedStore) InsertNotices(ctx context.Context, notices []*Notification) (int64, error) {
+	groups := make(map[int][]*Notification)
+	for _, n := range notices {
+		if n.ID == 0 {
+			n.ID = s.ids.next()
+		}
+		shard := s.sharder.Shard(n.AccountID)
+		groups[shard] = append(groups[shard], n)
+	}
+
+	var inserted int64
+	for shard, group := range groups {
+		count, err := s.shards[shard].InsertNotices(ctx, group)
+		inserted += count
+		if err != nil {
+			return inserted, fmt.Errorf("shard %d: %w", shard, err)
+		}
+	}
+	return inserted, nil
+}
+
+// GetNoticeByID 在所有分片中查找通知
+func (s *ShardedStore) GetNoticeByID(ctx context.Context, id uint64) (*Notification, error) {
+	n, _, err := s.locate(ctx, id)
+	return n, err
+}
+
+// locate 在所有分片中查找通知及其所在分片，不存在时返回gorm.ErrRecordNotFound
+func (s *ShardedStore) locate(ctx context.Context, id uint64) (*Notification, NotificationStore, error) {
+	found := make([]*Notification, len(s.shards))
+	err := s.ForEachShard(ctx, func(ctx context.Context, shard int, store NotificationStore) error {
+		n, err := store.GetNoticeByID(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		found[shard] = n
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for shard, n := range found {
+		if n != nil {
+			return n, s.shards[shard], nil
+		}
+	}
+	return nil, nil, gorm.ErrRecordNotFound
+}
+
+func (s *ShardedStore) GetNoticesByAccountID(ctx context.Context, accountID int64, status *int8, limit, offset int) ([]*Notification, error) {
+	return s.ShardFor(accountID).GetNoticesByAccountID(ctx, accountID, status, limit, offset)
+}
+
+func (s *ShardedStore) ListNotices(ctx context.Context, accountID int64, filter NoticeFilter, token string, limit int) (*NoticePage, error) {
+	return s.ShardFor(accountID).ListNotices(ctx, accountID, filter, token, limit)
+}
+
+// UpdateNoticeStatus 在通知所在的分片上更新状态，通知不存在时返回gorm.ErrRecordNotFound
+func (s *ShardedStore) UpdateNoticeStatus(ctx context.Context, id uint64, status int8) error {
+	_, store, err := s.locate(ctx, id)
+	if err != nil {
+		return err
+	}
+	return store.UpdateNoticeStatus(ctx, id, status)
+}
+
+func (s *ShardedStore) MarkReadByIDs(ctx context.Context, accountID int64, ids []uint64) (int64, error) {
+	return s.ShardFor(accountID).MarkReadByIDs(ctx, accountID, ids)
+}
+
+func (s *ShardedStore) MarkAllRead(ctx context.Context, accountID int64, notifyType *int8) (int64, error) {
+	return s.ShardFor(accountID).MarkAllRead(ctx, accountID, notifyType)
+}
+
+func (s *ShardedStore) MarkAllReadByCategory(ctx context.Context, accountID int64, category string) (int64, error) {
+	return s.ShardFor(accountID).MarkAllReadByCategory(ctx, accountID, category)
+}
+
+func (s *ShardedStore) DeleteNotice(ctx context.Context, accountID int64, id uint64) (bool, error) {
+	return s.ShardFor(accountID).DeleteNotice(ctx, accountID, id)
+}
+
+func (s *ShardedStore) DeleteByIDs(ctx context.Context, accountID int64, ids []uint64) (int64, error) {
+	return s.ShardFor(accountID).DeleteByIDs(ctx, accountID, ids)
+}
+
+func (s *ShardedStore) GetUnreadCount(ctx context.Context, accountID int64) (int64, error) {
+	return s.ShardFor(accountID).GetUnreadCount(ctx, accountID)
+}
+
+func (s *ShardedStore) GetUnreadCountsByType(ctx context.Context, accountID int64) (map[int8]int64, error) {
+	return s.ShardFor(accountID).GetUnreadCountsByType(ctx, accountID)
+}
+
+func (s *ShardedStore) GetUnreadSummary(ctx context.Context, accountID int64) (*UnreadSummary, error) {
+	return s.ShardFor(accountID).GetUnreadSummary(ctx, accountID)
+}
+
+// CollapseNotice 在账号所在的分片上按折叠键覆盖或插入通知，插入时使用全局唯一的ID
+func (s *ShardedStore) CollapseNotice(ctx context.Context, n *Notification, merge func(prev *Notification)) (bool, error) {
+	if n.ID == 0 {
+		n.ID = s.ids.next()
+	}
+	return s.ShardFor(n.AccountID).CollapseNotice(ctx, n, merge)
+}
+
+func (s *ShardedStore) RecordEngagement(ctx context.Context, accountID int64, id uint64, action EngagementAction) (bool, error) {
+	return s.ShardFor(accountID).RecordEngagement(ctx, accountID, id, action)
+}
+
+// GetEngagementReport 汇总所有分片的互动统计，按类型和活动合并后重新计算比率
+func (s *ShardedStore) GetEngagementReport(ctx context.Context, from, to time.Time) ([]*EngagementStat, error) {
+	reports := make([][]*EngagementStat, len(s.shards))
+	err := s.ForEachShard(ctx, func(ctx context.Context, shard int, store NotificationStore) error {
+		var err error
+		reports[shard], err = store.GetEngagementReport(ctx, from, to)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		notifyType   int8
+		activityName string
+	}
+	merged := make(map[key]*EngagementStat)
+	var stats []*EngagementStat
+	for _, report := range reports {
+		for _, stat := range report {
+			k := key{stat.Type, stat.ActivityName}
+			total, exist := merged[k]
+			if !exist {
+				total = &EngagementStat{Type: stat.Type, ActivityName: stat.ActivityName}
+				merged[k] = total
+				stats = append(stats, total)
+			}
+			total.Delivered += stat.Delivered
+			total.Read += stat.Read
+			total.Clicked += stat.Clicked
+			total.Dismissed += stat.Dismissed
+		}
+	}
+	for _, stat := range stats {
+		stat.ReadRate, stat.ClickRate = 0, 0
+		if stat.Delivered > 0 {
+			stat.ReadRate = float64(stat.Read) / float64(stat.Delivered)
+		}
+		if stat.Read > 0 {
+			stat.ClickRate = float64(stat.Clicked) / float64(stat.Read)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Type != stats[j].Type {
+			return stats[i].Type < stats[j].Type
+		}
+		return stats[i].ActivityName < stats[j].ActivityName
+	})
+	return stats, nil
+}
+
+// ArchiveNotices 依次归档各分片的通知，每次调用总共最多处理limit行，调用方循环调用直到返回0
+func (s *ShardedStore) ArchiveNotices(ctx context.Context, notifyType int8, before time.Time, limit int) (int64, error) {
+	var archived int64
+	for shard, store := range s.shards {
+		if archived >= int64(limit) {
+			break
+		}
+		count, err := store.ArchiveNotices(ctx, notifyType, before, limit-int(archived))
+		archived += count
+		if err != nil {
+			return archived, fmt.Errorf("shard %d: %w", shard, err)
+		}
+	}
+	return archived, nil
+}
@@ -0,0 +1,87 @@
+package repo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/repo/storetest"
+)
+
+// newShardedStore 创建由shards个SQLite库组成的分片存储
+func newShardedStore(t *testing.T, shards int, observer repo.UnreadObserver) *repo.ShardedStore {
+	t.Helper()
+	sharder, err := repo.NewModuloSharder(shards)
+	if err != nil {
+		t.Fatalf("NewModuloSharder: %v", err)
+	}
+	stores := make([]repo.NotificationStore, 0, shards)
+	for i := 0; i < shards; i++ {
+		noticeRepo := repo.NewNoticeRepository(newSQLiteDB(t))
+		if observer != nil {
+			noticeRepo.WithUnreadObserver(observer)
+		}
+		stores = append(stores, noticeRepo)
+	}
+	store, err := repo.NewShardedStore(sharder, stores...)
+	if err != nil {
+		t.Fatalf("NewShardedStore: %v", err)
+	}
+	return store
+}
+
+func TestShardedStoreConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, observer repo.UnreadObserver) repo.NotificationStore {
+		return newShardedStore(t, 3, observer)
+	})
+}
+
+func TestShardedStoreIDsUniqueAcrossShards(t *testing.T) {
+	ctx := context.Background()
+	store := newShardedStore(t, 3, nil)
+
+	// 各分片的自增ID都从1开始，分片存储分配的ID不能重复
+	var notices []*repo.Notification
+	for accountID := int64(1); accountID <= 30; accountID++ {
+		n := &repo.Notification{AccountID: accountID, Type: 1, Title: "t", Content: "c", CreatedAt: time.Now()}
+		if err := store.InsertNotice(ctx, n); err != nil {
+			t.Fatalf("InsertNotice: %v", err)
+		}
+		notices = append(notices, n)
+	}
+	batch := make([]*repo.Notification, 0, 30)
+	for accountID := int64(1); accountID <= 30; accountID++ {
+		batch = append(batch, &repo.Notification{AccountID: accountID, Type: 1, Title: "t", Content: "c", CreatedAt: time.Now()})
+	}
+	if _, err := store.InsertNotices(ctx, batch); err != nil {
+		t.Fatalf("InsertNotices: %v", err)
+	}
+	notices = append(notices, batch...)
+
+	seen := make(map[uint64]bool, len(notices))
+	for _, n := range notices {
+		if n.ID == 0 || seen[n.ID] {
+			t.Fatalf("notice for account %d got duplicate ID %d", n.AccountID, n.ID)
+		}
+		seen[n.ID] = true
+		got, err := store.GetNoticeByID(ctx, n.ID)
+		if err != nil || got.AccountID != n.AccountID {
+			t.Fatalf("GetNoticeByID(%d) = %+v, %v, want account %d", n.ID, got, err, n.AccountID)
+		}
+	}
+}
+
+func TestShardedStoreRejectsMismatchedSharder(t *testing.T) {
+	if _, err := repo.NewModuloSharder(0); !errors.Is(err, repo.ErrInvalidShards) {
+		t.Fatalf("NewModuloSharder(0) error = %v, want ErrInvalidShards", err)
+	}
+	if _, err := repo.NewConsistentHashSharder(0, 0); !errors.Is(err, repo.ErrInvalidShards) {
+		t.Fatalf("NewConsistentHashSharder(0) error = %v, want ErrInvalidShards", err)
+	}
+	sharder, _ := repo.NewModuloSharder(3)
+	if _, err := repo.NewShardedStore(sharder, repo.NewMemoryStore(), repo.NewMemoryStore()); !errors.Is(err, repo.ErrInvalidShards) {
+		t.Fatalf("NewShardedStore(3 shards expected, 2 given) error = %v, want ErrInvalidShards", err)
+	}
+}
@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereal3x/notice/migrate"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// NewSQLiteDB 打开SQLite数据库并执行迁移，用于本地开发和离线测试
+// path为数据库文件路径，传入":memory:"时使用内存数据库
+func NewSQLiteDB(path string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+		NowFunc: func() time.Time {
+			return time.Now().Local()
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+	// SQLite同一时间只允许一个写入者，内存数据库的每个连接互相独立，因此只使用一个连接
+	sqlDB.SetMaxOpenConns(1)
+
+	migrator, err := migrate.New(sqlDB, migrate.DialectSQLite)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to migrate sqlite database: %w", err)
+	}
+	return db, nil
+}
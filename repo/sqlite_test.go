@@ -0,0 +1,50 @@
+package repo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/repo/storetest"
+	"gorm.io/gorm"
+)
+
+// newSQLiteDB 打开已执行迁移的内存SQLite数据库，每次调用互相独立
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := repo.NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestNoticeRepositoryConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, observer repo.UnreadObserver) repo.NotificationStore {
+		return repo.NewNoticeRepository(newSQLiteDB(t)).WithUnreadObserver(observer)
+	})
+}
+
+func TestUpdateNoticeStatusNotFoundWithoutObserver(t *testing.T) {
+	ctx := context.Background()
+	r := repo.NewNoticeRepository(newSQLiteDB(t))
+	n := &repo.Notification{AccountID: 1, Type: 1, Category: "system", Title: "t", Content: "c"}
+	if err := r.InsertNotice(ctx, n); err != nil {
+		t.Fatalf("InsertNotice: %v", err)
+	}
+	// 状态未变化时同样成功
+	for _, status := range []int8{0, 0, 1} {
+		if err := r.UpdateNoticeStatus(ctx, n.ID, status); err != nil {
+			t.Fatalf("UpdateNoticeStatus(%d): %v", status, err)
+		}
+	}
+	if err := r.UpdateNoticeStatus(ctx, n.ID+1, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("UpdateNoticeStatus(missing) = %v, want ErrRecordNotFound", err)
+	}
+}
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+// NotificationStore 通知存储，处理器、保留任务、未读数缓存等依赖该接口而不是具体实现
+// NoticeRepository基于GORM实现，可运行在MySQL或SQLite之上；MemoryStore为纯内存实现，
+// 各实现的语义一致，由storetest包中的一致性测试保证
+//
+// 查询或更新单条通知时通知不存在返回gorm.ErrRecordNotFound；已删除的通知对除归档和互动统计外的操作不可见
+type NotificationStore interface {
+	InsertNotice(ctx context.Context, n *Notification) error
+	InsertNotices(ctx context.Context, notices []*Notification) (int64, error)
+	GetNoticeByID(ctx context.Context, id uint64) (*Notification, error)
+	GetNoticesByAccountID(ctx context.Context, accountID int64, status *int8, limit, offset int) ([]*Notification, error)
+	ListNotices(ctx context.Context, accountID int64, filter NoticeFilter, token string, limit int) (*NoticePage, error)
+	UpdateNoticeStatus(ctx context.Context, id uint64, status int8) error
+	MarkReadByIDs(ctx context.Context, accountID int64, ids []uint64) (int64, error)
+	MarkAllRead(ctx context.Context, accountID int64, notifyType *int8) (int64, error)
+	MarkAllReadByCategory(ctx context.Context, accountID int64, category string) (int64, error)
+	DeleteNotice(ctx context.Context, accountID int64, id uint64) (bool, error)
+	DeleteByIDs(ctx context.Context, accountID int64, ids []uint64) (int64, error)
+	GetUnreadCount(ctx context.Context, accountID int64) (int64, error)
+	GetUnreadCountsByType(ctx context.Context, accountID int64) (map[int8]int64, error)
+	GetUnreadSummary(ctx context.Context, accountID int64) (*UnreadSummary, error)
+	CollapseNotice(ctx context.Context, n *Notification, merge func(prev *Notification)) (bool, error)
+	RecordEngagement(ctx context.Context, accountID int64, id uint64, action EngagementAction) (bool, error)
+	GetEngagementReport(ctx context.Context, from, to time.Time) ([]*EngagementStat, error)
+	ArchiveNotices(ctx context.Context, notifyType int8, before time.Time, limit int) (int64, error)
+}
+
+var (
+	_ NotificationStore = (*NoticeRepository)(nil)
+	_ NotificationStore = (*MemoryStore)(nil)
+	_ NotificationStore = (*ShardedStore)(nil)
+)
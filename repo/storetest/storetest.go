@@ -0,0 +1,597 @@
+// Package storetest 提供repo.NotificationStore的一致性测试，各存储实现运行同一套用例以保证语义一致
+//
+// 在实现所在包的测试中调用：
+//
+//	func TestMemoryStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T, observer repo.UnreadObserver) repo.NotificationStore {
+//			return repo.NewMemoryStore().WithUnreadObserver(observer)
+//		})
+//	}
+package storetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereal3x/notice/repo"
+	"gorm.io/gorm"
+)
+
+// Factory 为每个用例创建一个空的存储，observer需设置为存储的未读数观察者
+type Factory func(t *testing.T, observer repo.UnreadObserver) repo.NotificationStore
+
+// Run 对存储实现运行全部一致性用例
+func Run(t *testing.T, newStore Factory) {
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, s *suite)
+	}{
+		{"InsertAndGet", testInsertAndGet},
+		{"GetNoticesByAccountID", testGetNoticesByAccountID},
+		{"ListNotices", testListNotices},
+		{"UnreadCounts", testUnreadCounts},
+		{"MarkRead", testMarkRead},
+		{"UpdateNoticeStatus", testUpdateNoticeStatus},
+		{"Delete", testDelete},
+		{"Collapse", testCollapse},
+		{"Engagement", testEngagement},
+		{"Archive", testArchive},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			observer := &countingObserver{counts: make(map[int64]map[int8]int64)}
+			c.fn(t, &suite{
+				store:    newStore(t, observer),
+				observer: observer,
+				ctx:      context.Background(),
+				// MySQL的timestamp只精确到秒
+				base: time.Now().Add(-time.Hour).Truncate(time.Second),
+			})
+		})
+	}
+}
+
+type suite struct {
+	store    repo.NotificationStore
+	observer *countingObserver
+	ctx      context.Context
+	base     time.Time
+}
+
+// countingObserver 累加观察者收到的未读数变化量
+type countingObserver struct {
+	mu     sync.Mutex
+	counts map[int64]map[int8]int64
+}
+
+func (o *countingObserver) UnreadChanged(ctx context.Context, accountID int64, deltas map[int8]int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.counts[accountID] == nil {
+		o.counts[accountID] = make(map[int8]int64)
+	}
+	for notifyType, delta := range deltas {
+		o.counts[accountID][notifyType] += delta
+	}
+}
+
+func (o *countingObserver) total(accountID int64) int64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var total int64
+	for _, count := range o.counts[accountID] {
+		total += count
+	}
+	return total
+}
+
+// insert 插入一条通知，创建时间为base之后的第seq秒
+func (s *suite) insert(t *testing.T, accountID int64, notifyType int8, seq int, opts ...func(n *repo.Notification)) *repo.Notification {
+	t.Helper()
+	n := &repo.Notification{
+		AccountID: accountID,
+		Type:      notifyType,
+		Category:  "audit",
+		Title:     "title",
+		Content:   "content",
+		CreatedAt: s.base.Add(time.Duration(seq) * time.Second),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	if err := s.store.InsertNotice(s.ctx, n); err != nil {
+		t.Fatalf("InsertNotice: %v", err)
+	}
+	return n
+}
+
+func expiredAt(at time.Time) func(n *repo.Notification) {
+	return func(n *repo.Notification) { n.ExpiresAt = &at }
+}
+
+// checkUnread 检查未读数查询结果与观察者累计的变化量一致
+func (s *suite) checkUnread(t *testing.T, accountID int64, want int64) {
+	t.Helper()
+	count, err := s.store.GetUnreadCount(s.ctx, accountID)
+	if err != nil {
+		t.Fatalf("GetUnreadCount: %v", err)
+	}
+	if count != want {
+		t.Fatalf("GetUnreadCount = %d, want %d", count, want)
+	}
+	if got := s.observer.total(accountID); got != want {
+		t.Fatalf("observer unread = %d, want %d", got, want)
+	}
+}
+
+func ids(notices []*repo.Notification) []uint64 {
+	result := make([]uint64, 0, len(notices))
+	for _, n := range notices {
+		result = append(result, n.ID)
+	}
+	return result
+}
+
+func equalIDs(t *testing.T, what string, got []*repo.Notification, want ...uint64) {
+	t.Helper()
+	gotIDs := ids(got)
+	if len(gotIDs) != len(want) {
+		t.Fatalf("%s = %v, want %v", what, gotIDs, want)
+	}
+	for i := range want {
+		if gotIDs[i] != want[i] {
+			t.Fatalf("%s = %v, want %v", what, gotIDs, want)
+		}
+	}
+}
+
+func testInsertAndGet(t *testing.T, s *suite) {
+	n := s.insert(t, 1, 1, 0, func(n *repo.Notification) {
+		n.ActivityName = "spring"
+		n.CollapseKey = "k"
+	})
+	if n.ID == 0 {
+		t.Fatal("InsertNotice did not assign an id")
+	}
+
+	got, err := s.store.GetNoticeByID(s.ctx, n.ID)
+	if err != nil {
+		t.Fatalf("GetNoticeByID: %v", err)
+	}
+	if got.AccountID != 1 || got.Title != "title" || got.ActivityName != "spring" || got.CollapseKey != "k" || got.Status != 0 {
+		t.Fatalf("GetNoticeByID = %+v", got)
+	}
+	if !got.CreatedAt.Equal(n.CreatedAt) {
+		t.Fatalf("CreatedAt = %v, want %v", got.CreatedAt, n.CreatedAt)
+	}
+	if got.ReadAt != nil || got.ClickedAt != nil || got.DismissedAt != nil {
+		t.Fatalf("new notice has engagement times: %+v", got)
+	}
+	if _, err := s.store.GetNoticeByID(s.ctx, n.ID+100); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetNoticeByID(missing) error = %v, want ErrRecordNotFound", err)
+	}
+
+	batch := []*repo.Notification{
+		{AccountID: 2, Type: 3, Title: "a", Content: "a"},
+		{AccountID: 2, Type: 3, Title: "b", Content: "b"},
+		{AccountID: 3, Type: 1, Title: "c", Content: "c"},
+	}
+	inserted, err := s.store.InsertNotices(s.ctx, batch)
+	if err != nil || inserted != 3 {
+		t.Fatalf("InsertNotices = %d, %v", inserted, err)
+	}
+	seen := map[uint64]bool{n.ID: true}
+	for _, b := range batch {
+		if b.ID == 0 || seen[b.ID] {
+			t.Fatalf("InsertNotices assigned id %d", b.ID)
+		}
+		seen[b.ID] = true
+		if b.CreatedAt.IsZero() {
+			t.Fatal("InsertNotices did not set CreatedAt")
+		}
+	}
+	if inserted, err := s.store.InsertNotices(s.ctx, nil); err != nil || inserted != 0 {
+		t.Fatalf("InsertNotices(nil) = %d, %v", inserted, err)
+	}
+	s.checkUnread(t, 2, 2)
+	s.checkUnread(t, 3, 1)
+}
+
+func testGetNoticesByAccountID(t *testing.T, s *suite) {
+	n1 := s.insert(t, 1, 1, 1)
+	n2 := s.insert(t, 1, 1, 2)
+	n3 := s.insert(t, 1, 2, 3)
+	s.insert(t, 1, 1, 4, expiredAt(time.Now().Add(-time.Minute)))
+	s.insert(t, 2, 1, 5)
+	if err := s.store.UpdateNoticeStatus(s.ctx, n2.ID, 1); err != nil {
+		t.Fatalf("UpdateNoticeStatus: %v", err)
+	}
+
+	all, err := s.store.GetNoticesByAccountID(s.ctx, 1, nil, 10, 0)
+	if err != nil {
+		t.Fatalf("GetNoticesByAccountID: %v", err)
+	}
+	equalIDs(t, "all notices", all, n3.ID, n2.ID, n1.ID)
+
+	unread := int8(0)
+	got, _ := s.store.GetNoticesByAccountID(s.ctx, 1, &unread, 10, 0)
+	equalIDs(t, "unread notices", got, n3.ID, n1.ID)
+
+	got, _ = s.store.GetNoticesByAccountID(s.ctx, 1, nil, 1, 1)
+	equalIDs(t, "second page", got, n2.ID)
+
+	got, _ = s.store.GetNoticesByAccountID(s.ctx, 1, nil, 10, 5)
+	equalIDs(t, "page past the end", got)
+}
+
+func testListNotices(t *testing.T, s *suite) {
+	var all []uint64
+	for i := 0; i < 7; i++ {
+		all = append(all, s.insert(t, 1, int8(1+i%2), i).ID)
+	}
+	// 与第6条同一时间创建，只能靠id区分先后
+	same := s.insert(t, 1, 2, 6)
+	all = append(all, same.ID)
+	s.insert(t, 1, 1, 8, expiredAt(time.Now().Add(-time.Minute)))
+	s.insert(t, 2, 1, 9)
+
+	newest := func(i int) uint64 { return all[len(all)-1-i] }
+
+	page, err := s.store.ListNotices(s.ctx, 1, repo.NoticeFilter{}, "", 3)
+	if err != nil {
+		t.Fatalf("ListNotices: %v", err)
+	}
+	equalIDs(t, "first page", page.Notices, newest(0), newest(1), newest(2))
+	if page.PrevCursor != "" || page.NextCursor == "" {
+		t.Fatalf("first page cursors: prev=%q next=%q", page.PrevCursor, page.NextCursor)
+	}
+
+	second, err := s.store.ListNotices(s.ctx, 1, repo.NoticeFilter{}, page.NextCursor, 3)
+	if err != nil {
+		t.Fatalf("ListNotices(next): %v", err)
+	}
+	equalIDs(t, "second page", second.Notices, newest(3), newest(4), newest(5))
+
+	last, _ := s.store.ListNotices(s.ctx, 1, repo.NoticeFilter{}, second.NextCursor, 3)
+	equalIDs(t, "last page", last.Notices, newest(6), newest(7))
+	if last.NextCursor != "" {
+		t.Fatalf("last page NextCursor = %q, want empty", last.NextCursor)
+	}
+
+	back, err := s.store.ListNotices(s.ctx, 1, repo.NoticeFilter{}, last.PrevCursor, 3)
+	if err != nil {
+		t.Fatalf("ListNotices(prev): %v", err)
+	}
+	equalIDs(t, "previous page", back.Notices, newest(3), newest(4), newest(5))
+
+	top, _ := s.store.ListNotices(s.ctx, 1, repo.NoticeFilter{}, back.PrevCursor, 3)
+	equalIDs(t, "back to first page", top.Notices, newest(0), newest(1), newest(2))
+	if top.PrevCursor != "" {
+		t.Fatalf("first page reached backward has PrevCursor %q", top.PrevCursor)
+	}
+
+	notifyType := int8(2)
+	filtered, _ := s.store.ListNotices(s.ctx, 1, repo.NoticeFilter{Type: &notifyType}, "", 10)
+	equalIDs(t, "filtered by type", filtered.Notices, same.ID, all[5], all[3], all[1])
+
+	if _, err := s.store.ListNotices(s.ctx, 1, repo.NoticeFilter{}, "not-a-cursor", 3); !errors.Is(err, repo.ErrInvalidCursor) {
+		t.Fatalf("ListNotices(bad cursor) error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func testUnreadCounts(t *testing.T, s *suite) {
+	s.insert(t, 1, 1, 0)
+	s.insert(t, 1, 1, 1)
+	s.insert(t, 1, 3, 2, func(n *repo.Notification) { n.Category = "reward" })
+	s.insert(t, 1, 3, 3, func(n *repo.Notification) { n.Status = 1 })
+	s.insert(t, 1, 1, 4, expiredAt(time.Now().Add(-time.Minute)))
+	s.insert(t, 2, 1, 5)
+	s.checkUnread(t, 1, 3)
+
+	byType, err := s.store.GetUnreadCountsByType(s.ctx, 1)
+	if err != nil {
+		t.Fatalf("GetUnreadCountsByType: %v", err)
+	}
+	if len(byType) != 2 || byType[1] != 2 || byType[3] != 1 {
+		t.Fatalf("GetUnreadCountsByType = %v", byType)
+	}
+
+	summary, err := s.store.GetUnreadSummary(s.ctx, 1)
+	if err != nil {
+		t.Fatalf("GetUnreadSummary: %v", err)
+	}
+	if summary.Total != 3 || summary.ByType[1] != 2 || summary.ByCategory["audit"] != 2 || summary.ByCategory["reward"] != 1 {
+		t.Fatalf("GetUnreadSummary = %+v", summary)
+	}
+
+	empty, _ := s.store.GetUnreadSummary(s.ctx, 9)
+	if empty.Total != 0 || len(empty.ByType) != 0 {
+		t.Fatalf("GetUnreadSummary(no notices) = %+v", empty)
+	}
+}
+
+func testMarkRead(t *testing.T, s *suite) {
+	n1 := s.insert(t, 1, 1, 0)
+	n2 := s.insert(t, 1, 1, 1)
+	n3 := s.insert(t, 1, 3, 2, func(n *repo.Notification) { n.Category = "reward" })
+	n4 := s.insert(t, 1, 3, 3, func(n *repo.Notification) { n.Category = "reward" })
+	n5 := s.insert(t, 1, 2, 4)
+	other := s.insert(t, 2, 1, 5)
+	s.checkUnread(t, 1, 5)
+
+	marked, err := s.store.MarkReadByIDs(s.ctx, 1, []uint64{n1.ID, other.ID})
+	if err != nil || marked != 1 {
+		t.Fatalf("MarkReadByIDs = %d, %v, want 1", marked, err)
+	}
+	s.checkUnread(t, 1, 4)
+	s.checkUnread(t, 2, 1)
+	if marked, _ := s.store.MarkReadByIDs(s.ctx, 1, []uint64{n1.ID}); marked != 0 {
+		t.Fatalf("MarkReadByIDs(already read) = %d, want 0", marked)
+	}
+	got, _ := s.store.GetNoticeByID(s.ctx, n1.ID)
+	if got.Status != 1 || got.ReadAt == nil {
+		t.Fatalf("notice not marked read: %+v", got)
+	}
+
+	marked, err = s.store.MarkAllReadByCategory(s.ctx, 1, "reward")
+	if err != nil || marked != 2 {
+		t.Fatalf("MarkAllReadByCategory = %d, %v, want 2", marked, err)
+	}
+	s.checkUnread(t, 1, 2)
+
+	notifyType := int8(2)
+	marked, err = s.store.MarkAllRead(s.ctx, 1, &notifyType)
+	if err != nil || marked != 1 {
+		t.Fatalf("MarkAllRead(type) = %d, %v, want 1", marked, err)
+	}
+	s.checkUnread(t, 1, 1)
+
+	marked, err = s.store.MarkAllRead(s.ctx, 1, nil)
+	if err != nil || marked != 1 {
+		t.Fatalf("MarkAllRead = %d, %v, want 1", marked, err)
+	}
+	s.checkUnread(t, 1, 0)
+	for _, id := range []uint64{n2.ID, n3.ID, n4.ID, n5.ID} {
+		if got, _ := s.store.GetNoticeByID(s.ctx, id); got.Status != 1 || got.ReadAt == nil {
+			t.Fatalf("notice %d not marked read: %+v", id, got)
+		}
+	}
+	s.checkUnread(t, 2, 1)
+}
+
+func testUpdateNoticeStatus(t *testing.T, s *suite) {
+	n := s.insert(t, 1, 1, 0)
+	if err := s.store.UpdateNoticeStatus(s.ctx, n.ID, 1); err != nil {
+		t.Fatalf("UpdateNoticeStatus: %v", err)
+	}
+	s.checkUnread(t, 1, 0)
+	got, _ := s.store.GetNoticeByID(s.ctx, n.ID)
+	if got.Status != 1 || got.ReadAt == nil {
+		t.Fatalf("notice not marked read: %+v", got)
+	}
+	readAt := *got.ReadAt
+
+	// 重复标记不改变首次阅读时间
+	if err := s.store.UpdateNoticeStatus(s.ctx, n.ID, 1); err != nil {
+		t.Fatalf("UpdateNoticeStatus: %v", err)
+	}
+	s.checkUnread(t, 1, 0)
+
+	if err := s.store.UpdateNoticeStatus(s.ctx, n.ID, 0); err != nil {
+		t.Fatalf("UpdateNoticeStatus: %v", err)
+	}
+	s.checkUnread(t, 1, 1)
+	got, _ = s.store.GetNoticeByID(s.ctx, n.ID)
+	if got.Status != 0 || got.ReadAt == nil || !got.ReadAt.Equal(readAt) {
+		t.Fatalf("marking unread changed read_at: %+v", got)
+	}
+
+	if err := s.store.UpdateNoticeStatus(s.ctx, n.ID+1000, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("UpdateNoticeStatus(missing) = %v, want ErrRecordNotFound", err)
+	}
+	s.store.DeleteNotice(s.ctx, 1, n.ID)
+	if err := s.store.UpdateNoticeStatus(s.ctx, n.ID, 1); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("UpdateNoticeStatus(deleted) = %v, want ErrRecordNotFound", err)
+	}
+	s.checkUnread(t, 1, 0)
+}
+
+func testDelete(t *testing.T, s *suite) {
+	n1 := s.insert(t, 1, 1, 0)
+	n2 := s.insert(t, 1, 1, 1)
+	n3 := s.insert(t, 1, 1, 2, func(n *repo.Notification) { n.Status = 1 })
+	other := s.insert(t, 2, 1, 3)
+
+	deleted, err := s.store.DeleteNotice(s.ctx, 1, n1.ID)
+	if err != nil || !deleted {
+		t.Fatalf("DeleteNotice = %v, %v", deleted, err)
+	}
+	s.checkUnread(t, 1, 1)
+	if deleted, _ := s.store.DeleteNotice(s.ctx, 1, n1.ID); deleted {
+		t.Fatal("DeleteNotice on a deleted notice returned true")
+	}
+	if deleted, _ := s.store.DeleteNotice(s.ctx, 1, other.ID); deleted {
+		t.Fatal("DeleteNotice on another account's notice returned true")
+	}
+	if _, err := s.store.GetNoticeByID(s.ctx, n1.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetNoticeByID(deleted) error = %v, want ErrRecordNotFound", err)
+	}
+
+	count, err := s.store.DeleteByIDs(s.ctx, 1, []uint64{n1.ID, n2.ID, n3.ID, other.ID})
+	if err != nil || count != 2 {
+		t.Fatalf("DeleteByIDs = %d, %v, want 2", count, err)
+	}
+	s.checkUnread(t, 1, 0)
+	s.checkUnread(t, 2, 1)
+	notices, _ := s.store.GetNoticesByAccountID(s.ctx, 1, nil, 10, 0)
+	equalIDs(t, "notices after delete", notices)
+	if marked, _ := s.store.MarkAllRead(s.ctx, 1, nil); marked != 0 {
+		t.Fatalf("MarkAllRead touched %d deleted notices", marked)
+	}
+}
+
+func testCollapse(t *testing.T, s *suite) {
+	collapse := func(accountID int64, title string, at time.Time) (*repo.Notification, *repo.Notification, bool) {
+		t.Helper()
+		n := &repo.Notification{AccountID: accountID, Type: 1, Category: "audit", Title: title, Content: title,
+			CollapseKey: "manuscript:1", CreatedAt: at, UpdatedAt: at}
+		var prev *repo.Notification
+		superseded, err := s.store.CollapseNotice(s.ctx, n, func(p *repo.Notification) { prev = p })
+		if err != nil {
+			t.Fatalf("CollapseNotice: %v", err)
+		}
+		return n, prev, superseded
+	}
+
+	n1, prev, superseded := collapse(1, "first", s.base)
+	if superseded || prev != nil || n1.ID == 0 {
+		t.Fatalf("CollapseNotice(first) = %v, prev %+v, id %d", superseded, prev, n1.ID)
+	}
+	s.insert(t, 2, 1, 1, func(n *repo.Notification) { n.CollapseKey = "manuscript:1" })
+	s.checkUnread(t, 1, 1)
+
+	n2, prev, superseded := collapse(1, "second", s.base.Add(time.Minute))
+	if !superseded || prev == nil || prev.ID != n1.ID || prev.Title != "first" || n2.ID != n1.ID {
+		t.Fatalf("CollapseNotice(second) = %v, prev %+v, id %d", superseded, prev, n2.ID)
+	}
+	got, _ := s.store.GetNoticeByID(s.ctx, n1.ID)
+	if got.Title != "second" || !got.CreatedAt.Equal(n2.CreatedAt) {
+		t.Fatalf("superseded notice = %+v", got)
+	}
+	s.checkUnread(t, 1, 1)
+	s.checkUnread(t, 2, 1)
+
+	s.store.MarkReadByIDs(s.ctx, 1, []uint64{n1.ID})
+	n3, prev, superseded := collapse(1, "third", s.base.Add(2*time.Minute))
+	if superseded || prev != nil || n3.ID == n1.ID {
+		t.Fatalf("CollapseNotice(after read) = %v, prev %+v, id %d", superseded, prev, n3.ID)
+	}
+	s.checkUnread(t, 1, 1)
+
+	// 并发写入同一折叠键只保留一条未读通知
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := &repo.Notification{AccountID: 3, Type: 1, Category: "audit", Title: "t", Content: "c",
+				CollapseKey: "manuscript:1", CreatedAt: s.base, UpdatedAt: s.base}
+			if _, err := s.store.CollapseNotice(s.ctx, n, func(*repo.Notification) {}); err != nil {
+				t.Errorf("CollapseNotice: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	s.checkUnread(t, 3, 1)
+
+	// 没有折叠键的通知不互相覆盖
+	for i := 0; i < 2; i++ {
+		n := &repo.Notification{AccountID: 4, Type: 1, Category: "audit", Title: "t", Content: "c", CreatedAt: s.base, UpdatedAt: s.base}
+		if superseded, err := s.store.CollapseNotice(s.ctx, n, func(*repo.Notification) {}); err != nil || superseded {
+			t.Fatalf("CollapseNotice(empty key) = %v, %v", superseded, err)
+		}
+	}
+	s.checkUnread(t, 4, 2)
+}
+
+func testEngagement(t *testing.T, s *suite) {
+	withActivity := func(name string) func(n *repo.Notification) {
+		return func(n *repo.Notification) { n.ActivityName = name }
+	}
+	n1 := s.insert(t, 1, 3, 0, withActivity("spring"))
+	n2 := s.insert(t, 1, 3, 1, withActivity("spring"))
+	n3 := s.insert(t, 1, 3, 2, withActivity("spring"))
+	s.insert(t, 1, 3, 3, withActivity("spring"))
+	n5 := s.insert(t, 1, 1, 4)
+
+	record := func(accountID int64, id uint64, action repo.EngagementAction, want bool) {
+		t.Helper()
+		ok, err := s.store.RecordEngagement(s.ctx, accountID, id, action)
+		if err != nil || ok != want {
+			t.Fatalf("RecordEngagement(%d, %d, %s) = %v, %v, want %v", accountID, id, action, ok, err, want)
+		}
+	}
+	record(1, n1.ID, repo.EngagementRead, true)
+	record(1, n1.ID, repo.EngagementRead, true)
+	record(1, n2.ID, repo.EngagementClick, true)
+	record(1, n2.ID, repo.EngagementClick, true)
+	record(1, n3.ID, repo.EngagementDismiss, true)
+	record(2, n5.ID, repo.EngagementClick, false)
+	record(1, n5.ID+100, repo.EngagementRead, false)
+	if _, err := s.store.RecordEngagement(s.ctx, 1, n1.ID, "share"); !errors.Is(err, repo.ErrUnknownEngagement) {
+		t.Fatalf("RecordEngagement(unknown) error = %v, want ErrUnknownEngagement", err)
+	}
+	s.checkUnread(t, 1, 2)
+
+	got, _ := s.store.GetNoticeByID(s.ctx, n2.ID)
+	if got.ReadAt == nil || got.ClickedAt == nil || got.DismissedAt != nil {
+		t.Fatalf("clicked notice = %+v", got)
+	}
+
+	// 已删除的通知仍计入统计
+	s.store.DeleteNotice(s.ctx, 1, n3.ID)
+	stats, err := s.store.GetEngagementReport(s.ctx, s.base, s.base.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("GetEngagementReport: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("GetEngagementReport returned %d groups, want 2", len(stats))
+	}
+	if stats[0].Type != 1 || stats[0].Delivered != 1 || stats[0].Read != 0 {
+		t.Fatalf("type 1 stats = %+v", stats[0])
+	}
+	spring := stats[1]
+	if spring.Type != 3 || spring.ActivityName != "spring" || spring.Delivered != 4 || spring.Read != 3 ||
+		spring.Clicked != 1 || spring.Dismissed != 1 || spring.ReadRate != 0.75 || spring.ClickRate != float64(1)/3 {
+		t.Fatalf("spring stats = %+v", spring)
+	}
+
+	later, _ := s.store.GetEngagementReport(s.ctx, s.base.Add(4*time.Second), s.base.Add(time.Minute))
+	if len(later) != 1 || later[0].Type != 1 {
+		t.Fatalf("GetEngagementReport(later window) = %+v", later)
+	}
+}
+
+func testArchive(t *testing.T, s *suite) {
+	old1 := s.insert(t, 1, 1, 0)
+	old2 := s.insert(t, 1, 1, 1)
+	old3 := s.insert(t, 2, 1, 2)
+	recent := s.insert(t, 1, 1, 10)
+	otherType := s.insert(t, 1, 2, 0)
+	s.store.DeleteNotice(s.ctx, 2, old3.ID)
+
+	before := s.base.Add(5 * time.Second)
+	archived, err := s.store.ArchiveNotices(s.ctx, 1, before, 2)
+	if err != nil || archived != 2 {
+		t.Fatalf("ArchiveNotices = %d, %v, want 2", archived, err)
+	}
+	archived, err = s.store.ArchiveNotices(s.ctx, 1, before, 2)
+	if err != nil || archived != 1 {
+		t.Fatalf("ArchiveNotices(second batch) = %d, %v, want 1", archived, err)
+	}
+	if archived, _ := s.store.ArchiveNotices(s.ctx, 1, before, 2); archived != 0 {
+		t.Fatalf("ArchiveNotices(done) = %d, want 0", archived)
+	}
+
+	// 归档的未读通知通知观察者，已删除的不重复扣减
+	s.checkUnread(t, 1, 2)
+	s.checkUnread(t, 2, 0)
+
+	for _, id := range []uint64{old1.ID, old2.ID} {
+		if _, err := s.store.GetNoticeByID(s.ctx, id); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("archived notice %d still readable: %v", id, err)
+		}
+	}
+	for _, id := range []uint64{recent.ID, otherType.ID} {
+		if _, err := s.store.GetNoticeByID(s.ctx, id); err != nil {
+			t.Fatalf("notice %d was archived: %v", id, err)
+		}
+	}
+	stats, _ := s.store.GetEngagementReport(s.ctx, s.base, before)
+	if len(stats) != 1 || stats[0].Type != 2 {
+		t.Fatalf("engagement report still counts archived notices: %+v", stats)
+	}
+}
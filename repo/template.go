@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationTemplate 通知模板
+type NotificationTemplate struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	Type      int8      `gorm:"column:type;not null;comment:通知类型: 1-稿件审核 2-认证审核 3-奖励发放 4-汇总" json:"type"`
+	Channel   string    `gorm:"column:channel;type:varchar(32);not null;comment:投递渠道: inbox/sms/email" json:"channel"`
+	Locale    string    `gorm:"column:locale;type:varchar(16);not null;comment:语言" json:"locale"`
+	Title     string    `gorm:"column:title;type:varchar(255);not null;comment:标题模板" json:"title"`
+	Content   string    `gorm:"column:content;type:text;not null;comment:内容模板" json:"content"`
+	Enabled   bool      `gorm:"column:enabled;not null;default:true;comment:是否启用" json:"enabled"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (NotificationTemplate) TableName() string {
+	return "tbl_notification_template"
+}
+
+type TemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewTemplateRepository(db *gorm.DB) *TemplateRepository {
+	return &TemplateRepository{db: db}
+}
+
+func (r *TemplateRepository) ListEnabledTemplates(ctx context.Context) ([]*NotificationTemplate, error) {
+	var templates []*NotificationTemplate
+	err := r.db.WithContext(ctx).Where("enabled = ?", true).Find(&templates).Error
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (r *TemplateRepository) SaveTemplate(ctx context.Context, t *NotificationTemplate) error {
+	return r.db.WithContext(ctx).Save(t).Error
+}
@@ -0,0 +1,186 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereal3x/notice/constants"
+	"gorm.io/gorm"
+)
+
+// UnreadObserver 未读数变化的观察者，用于增量维护未读数缓存
+// deltas为各通知类型未读数的变化量，回调在数据库变更提交之后执行
+type UnreadObserver interface {
+	UnreadChanged(ctx context.Context, accountID int64, deltas map[int8]int64)
+}
+
+// WithUnreadObserver 设置未读数观察者
+func (r *NoticeRepository) WithUnreadObserver(observer UnreadObserver) *NoticeRepository {
+	r.observer = observer
+	return r
+}
+
+// expired 通知是否已过期，过期通知不计入未读数
+func (n *Notification) expired() bool {
+	return n.ExpiresAt != nil && !time.Now().Before(*n.ExpiresAt)
+}
+
+// GetUnreadCountsByType 按通知类型统计账号的未读数，没有未读的类型不出现在结果中
+func (r *NoticeRepository) GetUnreadCountsByType(ctx context.Context, accountID int64) (map[int8]int64, error) {
+	return r.unreadByType(r.db.WithContext(ctx).Where("account_id = ? AND status = ?", accountID, constants.NOTIFICATION_STATUS_UNREAD))
+}
+
+// UnreadSummary 账号的未读数汇总
+type UnreadSummary struct {
+	Total      int64            `json:"total"`
+	ByType     map[int8]int64   `json:"by_type"`
+	ByCategory map[string]int64 `json:"by_category"`
+}
+
+func newUnreadSummary() *UnreadSummary {
+	return &UnreadSummary{ByType: make(map[int8]int64), ByCategory: make(map[string]int64)}
+}
+
+func (s *UnreadSummary) add(notifyType int8, category string, count int64) {
+	s.Total += count
+	s.ByType[notifyType] += count
+	s.ByCategory[category] += count
+}
+
+// scanUnreadSummary 执行按(type, category)分组的计数查询
+func scanUnreadSummary(query *gorm.DB) (*UnreadSummary, error) {
+	var rows []struct {
+		Type     int8
+		Category string
+		Count    int64
+	}
+	err := query.Select("type, category, COUNT(*) AS count").
+		Group("type, category").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	summary := newUnreadSummary()
+	for _, row := range rows {
+		summary.add(row.Type, row.Category, row.Count)
+	}
+	return summary, nil
+}
+
+// GetUnreadSummary 用一条分组查询统计账号按通知类型和分类的未读数
+func (r *NoticeRepository) GetUnreadSummary(ctx context.Context, accountID int64) (*UnreadSummary, error) {
+	return scanUnreadSummary(r.db.WithContext(ctx).Model(&Notification{}).
+		Where("account_id = ? AND status = ?", accountID, constants.NOTIFICATION_STATUS_UNREAD).
+		Scopes(notExpired))
+}
+
+// MarkAllReadByCategory 将账号下某一分类的未读通知全部标记为已读，返回更新行数
+func (r *NoticeRepository) MarkAllReadByCategory(ctx context.Context, accountID int64, category string) (int64, error) {
+	return r.changeUnread(ctx, accountID, func(db *gorm.DB) *gorm.DB {
+		return db.Where("account_id = ? AND status = ? AND category = ?", accountID, 0, category)
+	}, func(tx *gorm.DB) *gorm.DB {
+		return tx.Updates(statusUpdates(constants.NOTIFICATION_STATUS_READ))
+	})
+}
+
+// unreadByType 按类型统计query范围内未过期的未读通知
+func (r *NoticeRepository) unreadByType(query *gorm.DB) (map[int8]int64, error) {
+	var rows []struct {
+		Type  int8
+		Count int64
+	}
+	err := query.Model(&Notification{}).
+		Where("status = ?", constants.NOTIFICATION_STATUS_UNREAD).
+		Scopes(notExpired).
+		Select("type, COUNT(*) AS count").
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[int8]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+	return counts, nil
+}
+
+// changeUnread 执行可能减少未读数的变更，scope限定受影响的行，apply执行变更
+// 设置了观察者时在同一事务中先统计受影响的未读数，提交后通知观察者
+func (r *NoticeRepository) changeUnread(ctx context.Context, accountID int64, scope func(*gorm.DB) *gorm.DB, apply func(*gorm.DB) *gorm.DB) (int64, error) {
+	if r.observer == nil {
+		result := apply(scope(r.db.WithContext(ctx).Model(&Notification{})))
+		return result.RowsAffected, result.Error
+	}
+
+	var (
+		affected int64
+		counts   map[int8]int64
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if counts, err = r.unreadByType(scope(tx.Session(&gorm.Session{NewDB: true}))); err != nil {
+			return err
+		}
+		result := apply(scope(tx.Session(&gorm.Session{NewDB: true}).Model(&Notification{})))
+		affected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	notifyRemoved(ctx, r.observer, accountID, counts)
+	return affected, nil
+}
+
+// notifyRemoved 通知观察者账号各类型的未读通知减少了counts
+func notifyRemoved(ctx context.Context, observer UnreadObserver, accountID int64, counts map[int8]int64) {
+	if observer == nil || len(counts) == 0 {
+		return
+	}
+	deltas := make(map[int8]int64, len(counts))
+	for notifyType, count := range counts {
+		deltas[notifyType] = -count
+	}
+	observer.UnreadChanged(ctx, accountID, deltas)
+}
+
+// notifyInserted 按账号汇总新插入的未读通知并通知观察者
+func notifyInserted(ctx context.Context, observer UnreadObserver, notices ...*Notification) {
+	if observer == nil {
+		return
+	}
+	deltas := make(map[int64]map[int8]int64)
+	for _, n := range notices {
+		if n.Status != constants.NOTIFICATION_STATUS_UNREAD || n.expired() {
+			continue
+		}
+		if deltas[n.AccountID] == nil {
+			deltas[n.AccountID] = make(map[int8]int64)
+		}
+		deltas[n.AccountID][n.Type]++
+	}
+	for accountID, d := range deltas {
+		observer.UnreadChanged(ctx, accountID, d)
+	}
+}
+
+// notifyArchived 按账号汇总被归档移出的未读通知并通知观察者，已删除的通知不计入未读数
+func notifyArchived(ctx context.Context, observer UnreadObserver, notices []*Notification) {
+	if observer == nil {
+		return
+	}
+	removed := make(map[int64]map[int8]int64)
+	for _, n := range notices {
+		if n.DeletedAt.Valid || !n.unread() {
+			continue
+		}
+		if removed[n.AccountID] == nil {
+			removed[n.AccountID] = make(map[int8]int64)
+		}
+		removed[n.AccountID][n.Type]++
+	}
+	for accountID, counts := range removed {
+		notifyRemoved(ctx, observer, accountID, counts)
+	}
+}
@@ -0,0 +1,9 @@
+package retention_test
+
+import (
+	"testing"
+
+	"github.com/ethereal3x/notice/internal/testutil"
+)
+
+func TestMain(m *testing.M) { testutil.Main(m) }
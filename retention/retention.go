@@ -0,0 +1,104 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/repo"
+	"go.uber.org/zap"
+)
+
+// DefaultBatchSize 每批归档的默认行数，保持事务短小避免长时间锁表
+const DefaultBatchSize = 500
+
+// Job 通知保留任务，将超过保留期的通知移入归档表并从通知表删除
+type Job struct {
+	repo      repo.NotificationStore
+	policies  map[int8]time.Duration // 通知类型 -> 保留时长，未配置的类型永久保留
+	batchSize int
+	pause     time.Duration // 批次之间的间隔，降低对线上库的压力
+}
+
+// NewJob 初始化保留任务
+func NewJob(repo repo.NotificationStore, policies map[int8]time.Duration) *Job {
+	return &Job{
+		repo:      repo,
+		policies:  policies,
+		batchSize: DefaultBatchSize,
+		pause:     100 * time.Millisecond,
+	}
+}
+
+// WithBatchSize 设置每批归档的行数
+func (j *Job) WithBatchSize(size int) *Job {
+	if size > 0 {
+		j.batchSize = size
+	}
+	return j
+}
+
+// Run 执行一次归档，按类型分批处理直到没有过期通知或ctx取消，返回归档总行数
+// 某个类型归档失败时记录日志并继续处理其余类型，最后返回所有失败的错误
+func (j *Job) Run(ctx context.Context) (int64, error) {
+	var (
+		total int64
+		errs  []error
+	)
+	for notifyType, keep := range j.policies {
+		n, err := j.archive(ctx, notifyType, time.Now().Add(-keep))
+		total += n
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("archive type %d: %w", notifyType, err))
+		}
+	}
+	if total > 0 {
+		logger.ContextInfo(ctx, "retention.Job.Run: notifications archived", zap.Int64("count", total))
+	}
+	return total, errors.Join(errs...)
+}
+
+// archive 分批归档一个类型在before之前创建的通知，返回归档行数
+func (j *Job) archive(ctx context.Context, notifyType int8, before time.Time) (int64, error) {
+	var total int64
+	for {
+		n, err := j.repo.ArchiveNotices(ctx, notifyType, before, j.batchSize)
+		if err != nil {
+			logger.ContextError(ctx, "retention.Job.Run: archive notifications failed",
+				zap.Int8("type", notifyType),
+				zap.Time("before", before),
+				zap.Error(err))
+			return total, err
+		}
+		total += n
+		if n < int64(j.batchSize) {
+			return total, nil
+		}
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(j.pause):
+		}
+	}
+}
+
+// Start 后台按interval周期执行归档，ctx取消后停止
+func (j *Job) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, _ = j.Run(ctx)
+			}
+		}
+	}()
+}
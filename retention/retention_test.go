@@ -0,0 +1,88 @@
+package retention_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/retention"
+)
+
+// failingStore 记录每个类型的归档批次，对failType的归档返回错误
+type failingStore struct {
+	repo.NotificationStore
+	failType int8
+	batches  map[int8]int
+}
+
+func (s *failingStore) ArchiveNotices(ctx context.Context, notifyType int8, before time.Time, limit int) (int64, error) {
+	s.batches[notifyType]++
+	if notifyType == s.failType {
+		return 0, errors.New("archive table unavailable")
+	}
+	return s.NotificationStore.ArchiveNotices(ctx, notifyType, before, limit)
+}
+
+func TestRunArchivesInBatchesAndContinuesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	db, err := repo.NewSQLiteDB(":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	store := &failingStore{NotificationStore: repo.NewNoticeRepository(db), failType: 2, batches: make(map[int8]int)}
+
+	old, recent := time.Now().Add(-48*time.Hour), time.Now()
+	insert := func(notifyType int8, at time.Time, count int) {
+		for i := 0; i < count; i++ {
+			n := &repo.Notification{AccountID: 1, Type: notifyType, Category: "system", Title: "t", Content: "c", CreatedAt: at, UpdatedAt: at}
+			if err := store.InsertNotice(ctx, n); err != nil {
+				t.Fatalf("InsertNotice: %v", err)
+			}
+		}
+	}
+	insert(1, old, 5)
+	insert(1, recent, 1)
+	insert(2, old, 2)
+	insert(3, old, 3)
+
+	job := retention.NewJob(store, map[int8]time.Duration{1: 24 * time.Hour, 2: 24 * time.Hour, 3: 24 * time.Hour}).WithBatchSize(2)
+	archived, err := job.Run(ctx)
+	if err == nil {
+		t.Fatal("Run succeeded, want the archive error of type 2")
+	}
+	if archived != 8 {
+		t.Fatalf("archived = %d, want 8", archived)
+	}
+	// 5条分3批，3条分2批
+	if store.batches[1] != 3 || store.batches[3] != 2 {
+		t.Fatalf("archive batches = %v", store.batches)
+	}
+
+	countByType := func(model any) map[int8]int64 {
+		var rows []struct {
+			Type  int8
+			Count int64
+		}
+		if err := db.Model(model).Select("type, COUNT(*) AS count").Group("type").Scan(&rows).Error; err != nil {
+			t.Fatalf("count rows: %v", err)
+		}
+		counts := make(map[int8]int64)
+		for _, r := range rows {
+			counts[r.Type] = r.Count
+		}
+		return counts
+	}
+	if got := countByType(&repo.ArchivedNotification{}); got[1] != 5 || got[2] != 0 || got[3] != 3 {
+		t.Fatalf("archived rows by type = %v", got)
+	}
+	if got := countByType(&repo.Notification{}); got[1] != 1 || got[2] != 2 || got[3] != 0 {
+		t.Fatalf("remaining rows by type = %v", got)
+	}
+}
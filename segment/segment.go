@@ -0,0 +1,109 @@
+package segment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnknownSegment 分群未注册
+var ErrUnknownSegment = errors.New("unknown segment")
+
+// Resolver 分群解析器，按账号ID升序分批返回分群内的账号
+// after为上一批的最后一个账号ID（首批为0），返回不足limit个表示已取完
+type Resolver interface {
+	Resolve(ctx context.Context, after int64, limit int) ([]int64, error)
+}
+
+// ResolverFunc 回调形式的解析器
+type ResolverFunc func(ctx context.Context, after int64, limit int) ([]int64, error)
+
+func (f ResolverFunc) Resolve(ctx context.Context, after int64, limit int) ([]int64, error) {
+	return f(ctx, after, limit)
+}
+
+// StaticResolver 固定账号列表
+type StaticResolver struct {
+	ids []int64
+}
+
+// NewStaticResolver 使用账号列表初始化，列表会被排序去重
+func NewStaticResolver(ids []int64) *StaticResolver {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	unique := sorted[:0]
+	for i, id := range sorted {
+		if i == 0 || id != sorted[i-1] {
+			unique = append(unique, id)
+		}
+	}
+	return &StaticResolver{ids: unique}
+}
+
+func (r *StaticResolver) Resolve(ctx context.Context, after int64, limit int) ([]int64, error) {
+	start := sort.Search(len(r.ids), func(i int) bool { return r.ids[i] > after })
+	end := min(start+limit, len(r.ids))
+	return append([]int64(nil), r.ids[start:end]...), nil
+}
+
+// query 分群查询语句，类型不导出，包外只能传入字符串常量
+// 查询会被拼接进外层的分页语句，不能来自配置、数据库或用户输入；参数通过args传入
+type query string
+
+// SQLResolver 通过SQL查询解析分群，查询需返回account_id列
+// 例如: SELECT DISTINCT account_id FROM tbl_manuscript WHERE activity_id = ?
+// 分群需在代码中通过Registry.Register注册，活动推送任务只按名称引用已注册的分群
+type SQLResolver struct {
+	db    *gorm.DB
+	query query
+	args  []interface{}
+}
+
+// NewSQLResolver q只接受字符串常量，传入字符串变量无法通过编译
+func NewSQLResolver(db *gorm.DB, q query, args ...interface{}) *SQLResolver {
+	return &SQLResolver{db: db, query: q, args: args}
+}
+
+func (r *SQLResolver) Resolve(ctx context.Context, after int64, limit int) ([]int64, error) {
+	var ids []int64
+	args := append(append([]interface{}(nil), r.args...), after, limit)
+	err := r.db.WithContext(ctx).
+		Raw(fmt.Sprintf("SELECT account_id FROM (%s) s WHERE account_id > ? ORDER BY account_id LIMIT ?", r.query), args...).
+		Scan(&ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("resolve segment failed: %w", err)
+	}
+	return ids, nil
+}
+
+// Registry 分群注册表，按名称查找解析器
+type Registry struct {
+	mu        sync.RWMutex
+	resolvers map[string]Resolver
+}
+
+func NewRegistry() *Registry {
+	return &Registry{resolvers: make(map[string]Resolver)}
+}
+
+// Register 注册分群，同名分群会被覆盖
+func (r *Registry) Register(name string, resolver Resolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolvers[name] = resolver
+}
+
+// Lookup 查找分群解析器
+func (r *Registry) Lookup(name string) (Resolver, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	resolver, exist := r.resolvers[name]
+	if !exist {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSegment, name)
+	}
+	return resolver, nil
+}
@@ -0,0 +1,115 @@
+package template
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/repo"
+)
+
+//go:embed defaults/*.tmpl
+var defaultTemplates embed.FS
+
+// FSLoader 从文件系统加载模板
+// 文件名格式为 <通知类型>.<渠道>.<语言>.tmpl，例如 1.inbox.zh-CN.tmpl；
+// 省略语言的 <通知类型>.<渠道>.tmpl 为通用模板，文案全部通过t函数引用消息目录，适用于所有语言
+// 文件第一行为标题模板，其余为内容模板
+type FSLoader struct {
+	fsys fs.FS
+	dir  string
+}
+
+// NewDefaultLoader 加载内置的默认模板
+func NewDefaultLoader() *FSLoader {
+	return &FSLoader{fsys: defaultTemplates, dir: "defaults"}
+}
+
+// NewFileLoader 从本地目录加载模板
+func NewFileLoader(dir string) *FSLoader {
+	return &FSLoader{fsys: os.DirFS(dir), dir: "."}
+}
+
+func (l *FSLoader) Load(ctx context.Context) ([]*Template, error) {
+	entries, err := fs.ReadDir(l.fsys, l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read template dir failed: %w", err)
+	}
+
+	var templates []*Template
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tmpl") {
+			continue
+		}
+		key, err := parseFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		data, err := fs.ReadFile(l.fsys, path.Join(l.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read template %s failed: %w", entry.Name(), err)
+		}
+		title, content, _ := strings.Cut(string(data), "\n")
+		templates = append(templates, &Template{
+			Key:     key,
+			Title:   title,
+			Content: content,
+		})
+	}
+	return templates, nil
+}
+
+// parseFileName 解析 <通知类型>.<渠道>.<语言>.tmpl，省略语言时为通用模板
+func parseFileName(name string) (Key, error) {
+	parts := strings.Split(strings.TrimSuffix(name, ".tmpl"), ".")
+	if len(parts) == 2 {
+		parts = append(parts, "")
+	}
+	if len(parts) != 3 {
+		return Key{}, fmt.Errorf("invalid template file name: %s", name)
+	}
+	notifyType, err := strconv.ParseInt(parts[0], 10, 8)
+	if err != nil {
+		return Key{}, fmt.Errorf("invalid notification type in template file name %s: %w", name, err)
+	}
+	return Key{
+		Type:    int8(notifyType),
+		Channel: channel.Channel(parts[1]),
+		Locale:  parts[2],
+	}, nil
+}
+
+// DBLoader 从数据库tbl_notification_template加载模板
+type DBLoader struct {
+	repo *repo.TemplateRepository
+}
+
+func NewDBLoader(repo *repo.TemplateRepository) *DBLoader {
+	return &DBLoader{repo: repo}
+}
+
+func (l *DBLoader) Load(ctx context.Context) ([]*Template, error) {
+	rows, err := l.repo.ListEnabledTemplates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("query templates failed: %w", err)
+	}
+	templates := make([]*Template, 0, len(rows))
+	for _, row := range rows {
+		templates = append(templates, &Template{
+			Key: Key{
+				Type:    row.Type,
+				Channel: channel.Channel(row.Channel),
+				Locale:  row.Locale,
+			},
+			Title:   row.Title,
+			Content: row.Content,
+		})
+	}
+	return templates, nil
+}
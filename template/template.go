@@ -0,0 +1,217 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/i18n"
+	"go.uber.org/zap"
+)
+
+// DefaultLocale 默认语言
+const DefaultLocale = i18n.DefaultLocale
+
+// ErrTemplateNotFound 未找到匹配的模板
+var ErrTemplateNotFound = errors.New("template not found")
+
+// Key 模板键：通知类型 + 渠道 + 语言
+type Key struct {
+	Type    int8            // 通知类型，见constants.NOTIFICATION_TYPE_*
+	Channel channel.Channel // 投递渠道
+	Locale  string          // 语言，例如"zh-CN"；为空表示通用模板
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%d.%s.%s", k.Type, k.Channel, k.Locale)
+}
+
+// Template 模板定义，Title和Content均为text/template语法
+type Template struct {
+	Key
+	Title   string
+	Content string
+}
+
+// Translator 多语言翻译器，i18n.Bundle实现了该接口
+type Translator interface {
+	// Chain 返回语言回退链
+	Chain(locale string) []string
+
+	// T 翻译消息
+	T(locale, key string, args ...any) string
+}
+
+// Loader 模板加载器
+type Loader interface {
+	// Load 加载全部模板
+	Load(ctx context.Context) ([]*Template, error)
+}
+
+// compiled 编译后的模板
+type compiled struct {
+	title   *texttemplate.Template
+	content *texttemplate.Template
+}
+
+// Engine 模板引擎，按加载器顺序合并模板，后加载的覆盖先加载的
+type Engine struct {
+	loaders    []Loader
+	translator Translator
+	funcs      texttemplate.FuncMap
+	mu         sync.RWMutex
+	templates  map[Key]*compiled
+}
+
+// NewEngine 创建模板引擎，需调用Reload完成首次加载
+func NewEngine(loaders ...Loader) *Engine {
+	return &Engine{
+		loaders: loaders,
+		funcs: texttemplate.FuncMap{
+			// t 在渲染时绑定到当前语言，见Render
+			"t": func(key string, args ...any) string { return key },
+		},
+		templates: make(map[Key]*compiled),
+	}
+}
+
+// WithTranslator 设置翻译器，模板中可通过 {{t "key" args...}} 引用消息目录
+func (e *Engine) WithTranslator(translator Translator) *Engine {
+	e.translator = translator
+	return e
+}
+
+// Funcs 注册模板函数，需在Reload之前调用
+func (e *Engine) Funcs(funcs texttemplate.FuncMap) *Engine {
+	for name, fn := range funcs {
+		e.funcs[name] = fn
+	}
+	return e
+}
+
+// Reload 重新加载并编译全部模板，任一模板出错时保留旧模板
+func (e *Engine) Reload(ctx context.Context) error {
+	templates := make(map[Key]*compiled)
+	for _, loader := range e.loaders {
+		list, err := loader.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("load templates failed: %w", err)
+		}
+		for _, t := range list {
+			c, err := e.compile(t)
+			if err != nil {
+				return fmt.Errorf("compile template %s failed: %w", t.Key, err)
+			}
+			templates[t.Key] = c
+		}
+	}
+
+	e.mu.Lock()
+	e.templates = templates
+	e.mu.Unlock()
+	return nil
+}
+
+// Watch 周期性重新加载模板，直到ctx取消
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.Reload(ctx); err != nil {
+					logger.ContextError(ctx, "template.Engine.Watch: reload templates failed, keep old templates",
+						zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Render 渲染标题和内容，找不到指定语言的模板时沿回退链查找，最后使用通用模板，外部渠道可回退到站内信模板
+func (e *Engine) Render(key Key, data any) (title, content string, err error) {
+	c, err := e.lookup(key)
+	if err != nil {
+		return "", "", err
+	}
+	funcs := texttemplate.FuncMap{"t": e.translate(key.Locale)}
+	if title, err = execute(c.title, funcs, data); err != nil {
+		return "", "", fmt.Errorf("render title %s failed: %w", key, err)
+	}
+	if content, err = execute(c.content, funcs, data); err != nil {
+		return "", "", fmt.Errorf("render content %s failed: %w", key, err)
+	}
+	return title, content, nil
+}
+
+func (e *Engine) lookup(key Key) (*compiled, error) {
+	chain := []string{key.Locale, DefaultLocale}
+	if e.translator != nil {
+		chain = e.translator.Chain(key.Locale)
+	}
+	// 回退链上都没有专用模板时使用通用模板
+	chain = append(chain, "")
+
+	// 外部渠道未配置专用模板时复用站内信模板
+	channels := []channel.Channel{key.Channel}
+	if key.Channel != channel.ChannelInbox {
+		channels = append(channels, channel.ChannelInbox)
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, ch := range channels {
+		for _, locale := range chain {
+			candidate := Key{Type: key.Type, Channel: ch, Locale: locale}
+			if c, ok := e.templates[candidate]; ok {
+				return c, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, key)
+}
+
+// translate 返回绑定到指定语言的t函数
+func (e *Engine) translate(locale string) func(key string, args ...any) string {
+	return func(key string, args ...any) string {
+		if e.translator == nil {
+			return key
+		}
+		return e.translator.T(locale, key, args...)
+	}
+}
+
+func (e *Engine) compile(t *Template) (*compiled, error) {
+	title, err := texttemplate.New("title").Funcs(e.funcs).Option("missingkey=zero").Parse(t.Title)
+	if err != nil {
+		return nil, err
+	}
+	content, err := texttemplate.New("content").Funcs(e.funcs).Option("missingkey=zero").Parse(t.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &compiled{title: title, content: content}, nil
+}
+
+// execute 克隆模板并绑定渲染期函数后执行，避免并发渲染互相影响
+func execute(t *texttemplate.Template, funcs texttemplate.FuncMap, data any) (string, error) {
+	t, err := t.Clone()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Funcs(funcs).Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,160 @@
+package template
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereal3x/notice/channel"
+	"github.com/ethereal3x/notice/i18n"
+)
+
+// sliceLoader 返回可替换的模板列表
+type sliceLoader struct {
+	templates []*Template
+}
+
+func (l *sliceLoader) Load(ctx context.Context) ([]*Template, error) {
+	return l.templates, nil
+}
+
+func newEngine(t *testing.T, templates ...*Template) (*Engine, *sliceLoader) {
+	t.Helper()
+	loader := &sliceLoader{templates: templates}
+	e := NewEngine(loader)
+	if err := e.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	return e, loader
+}
+
+func tpl(notifyType int8, ch channel.Channel, locale, title string) *Template {
+	return &Template{Key: Key{Type: notifyType, Channel: ch, Locale: locale}, Title: title, Content: "{{.}}"}
+}
+
+func TestRenderFallbackOrder(t *testing.T) {
+	e, _ := newEngine(t,
+		tpl(1, channel.ChannelInbox, DefaultLocale, "inbox default"),
+		tpl(1, channel.ChannelInbox, "en-US", "inbox en"),
+		tpl(1, channel.ChannelEmail, DefaultLocale, "email default"),
+		tpl(2, channel.ChannelInbox, DefaultLocale, "type 2 inbox default"),
+	)
+	tests := []struct {
+		key  Key
+		want string
+	}{
+		{Key{Type: 1, Channel: channel.ChannelInbox, Locale: "en-US"}, "inbox en"},
+		// 语言回退到默认语言
+		{Key{Type: 1, Channel: channel.ChannelInbox, Locale: "ja-JP"}, "inbox default"},
+		// 同一渠道的默认语言优先于站内信的指定语言
+		{Key{Type: 1, Channel: channel.ChannelEmail, Locale: "en-US"}, "email default"},
+		// 外部渠道没有模板时回退到站内信
+		{Key{Type: 1, Channel: channel.ChannelSMS, Locale: "en-US"}, "inbox en"},
+		{Key{Type: 2, Channel: channel.ChannelSMS, Locale: "en-US"}, "type 2 inbox default"},
+	}
+	for _, tt := range tests {
+		title, _, err := e.Render(tt.key, "data")
+		if err != nil || title != tt.want {
+			t.Fatalf("Render(%s) = %q, %v, want %q", tt.key, title, err, tt.want)
+		}
+	}
+}
+
+func TestRenderLocaleNeutralTemplate(t *testing.T) {
+	e, _ := newEngine(t,
+		tpl(1, channel.ChannelInbox, "", "neutral"),
+		tpl(1, channel.ChannelInbox, "ja-JP", "ja"),
+		tpl(1, channel.ChannelSMS, "", "sms neutral"),
+	)
+	tests := []struct {
+		key  Key
+		want string
+	}{
+		{Key{Type: 1, Channel: channel.ChannelInbox, Locale: "en-US"}, "neutral"},
+		{Key{Type: 1, Channel: channel.ChannelInbox, Locale: "ja-JP"}, "ja"},
+		// 同一渠道的通用模板优先于站内信模板
+		{Key{Type: 1, Channel: channel.ChannelSMS, Locale: "ja-JP"}, "sms neutral"},
+		{Key{Type: 1, Channel: channel.ChannelEmail, Locale: "en-US"}, "neutral"},
+	}
+	for _, tt := range tests {
+		title, _, err := e.Render(tt.key, nil)
+		if err != nil || title != tt.want {
+			t.Fatalf("Render(%s) = %q, %v, want %q", tt.key, title, err, tt.want)
+		}
+	}
+}
+
+func TestRenderMissingTemplate(t *testing.T) {
+	e, _ := newEngine(t, tpl(1, channel.ChannelInbox, DefaultLocale, "title"))
+	if _, _, err := e.Render(Key{Type: 9, Channel: channel.ChannelInbox, Locale: DefaultLocale}, nil); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("Render(missing) error = %v, want ErrTemplateNotFound", err)
+	}
+	// 站内信模板不会回退到外部渠道模板
+	e, _ = newEngine(t, tpl(1, channel.ChannelEmail, DefaultLocale, "title"))
+	if _, _, err := e.Render(Key{Type: 1, Channel: channel.ChannelInbox, Locale: DefaultLocale}, nil); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("Render(inbox without template) error = %v, want ErrTemplateNotFound", err)
+	}
+}
+
+func TestReloadSwapsTemplates(t *testing.T) {
+	ctx := context.Background()
+	key := Key{Type: 1, Channel: channel.ChannelInbox, Locale: DefaultLocale}
+	e, loader := newEngine(t, tpl(1, channel.ChannelInbox, DefaultLocale, "old"))
+
+	loader.templates = []*Template{tpl(1, channel.ChannelInbox, DefaultLocale, "new")}
+	if title, _, _ := e.Render(key, nil); title != "old" {
+		t.Fatalf("Render before reload = %q, want old", title)
+	}
+	if err := e.Reload(ctx); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if title, _, _ := e.Render(key, nil); title != "new" {
+		t.Fatalf("Render after reload = %q, want new", title)
+	}
+
+	// 编译失败时保留旧模板
+	loader.templates = []*Template{tpl(1, channel.ChannelInbox, DefaultLocale, "{{.Broken")}
+	if err := e.Reload(ctx); err == nil {
+		t.Fatal("Reload with broken template succeeded")
+	}
+	if title, _, _ := e.Render(key, nil); title != "new" {
+		t.Fatalf("Render after failed reload = %q, want new", title)
+	}
+}
+
+func TestRenderTranslatesWithLocale(t *testing.T) {
+	bundle := i18n.NewBundle(nil)
+	bundle.AddCatalog(DefaultLocale, i18n.Catalog{"greeting": "你好，%s"})
+	bundle.AddCatalog("en-US", i18n.Catalog{"greeting": "Hello, %s"})
+	e := NewEngine(&sliceLoader{templates: []*Template{
+		{Key: Key{Type: 1, Channel: channel.ChannelInbox, Locale: DefaultLocale}, Title: `{{t "greeting" .}}`, Content: `{{t "missing.key"}}`},
+	}}).WithTranslator(bundle)
+	if err := e.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	// 回退到默认语言的模板，消息仍按请求的语言翻译
+	title, content, err := e.Render(Key{Type: 1, Channel: channel.ChannelInbox, Locale: "en-US"}, "Alice")
+	if err != nil || title != "Hello, Alice" || content != "missing.key" {
+		t.Fatalf("Render = %q, %q, %v", title, content, err)
+	}
+}
+
+func TestDefaultTemplatesRenderPerLocale(t *testing.T) {
+	bundle, err := i18n.NewDefaultBundle()
+	if err != nil {
+		t.Fatalf("NewDefaultBundle: %v", err)
+	}
+	e := NewEngine(NewDefaultLoader()).WithTranslator(bundle)
+	if err := e.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	data := map[string]any{"Event": map[string]any{"ActivityName": "spring", "AwardAmount": 100}}
+	for locale, want := range map[string]string{"en-US": "Reward granted", DefaultLocale: "奖励发放通知"} {
+		for _, ch := range []channel.Channel{channel.ChannelInbox, channel.ChannelSMS, channel.ChannelEmail} {
+			title, _, err := e.Render(Key{Type: 3, Channel: ch, Locale: locale}, data)
+			if err != nil || title != want {
+				t.Fatalf("Render(%s, %s) = %q, %v, want %q", ch, locale, title, err, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package unread
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Cache 未读数缓存，按账号保存各通知类型的未读数
+type Cache interface {
+	// Get 读取账号的未读数，未命中时返回false
+	Get(ctx context.Context, accountID int64) (map[int8]int64, bool, error)
+	// Set 写入账号的完整未读数
+	Set(ctx context.Context, accountID int64, counts map[int8]int64) error
+	// Add 在已缓存的未读数上累加变化量，未缓存的账号忽略
+	Add(ctx context.Context, accountID int64, deltas map[int8]int64) error
+	// Delete 删除账号的缓存
+	Delete(ctx context.Context, accountID int64) error
+}
+
+// LRUCache 进程内LRU缓存，条目超过ttl后视为未命中
+// 只能收到本实例写入引起的增量，其他副本上的已读、删除在ttl内不可见，多副本部署时ttl应保持在秒级
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	items    map[int64]*list.Element
+	order    *list.List
+}
+
+type lruEntry struct {
+	accountID int64
+	counts    map[int8]int64
+	expiresAt time.Time
+}
+
+// NewLRUCache 初始化LRU缓存，最多保存capacity个账号
+func NewLRUCache(capacity int, ttl time.Duration) *LRUCache {
+	return &LRUCache{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[int64]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *LRUCache) Get(ctx context.Context, accountID int64) (map[int8]int64, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, exist := c.items[accountID]
+	if !exist {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.remove(elem)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(elem)
+	return copyCounts(entry.counts), true, nil
+}
+
+func (c *LRUCache) Set(ctx context.Context, accountID int64, counts map[int8]int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &lruEntry{accountID: accountID, counts: copyCounts(counts), expiresAt: time.Now().Add(c.ttl)}
+	if elem, exist := c.items[accountID]; exist {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return nil
+	}
+	c.items[accountID] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *LRUCache) Add(ctx context.Context, accountID int64, deltas map[int8]int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, exist := c.items[accountID]
+	if !exist {
+		return nil
+	}
+	entry := elem.Value.(*lruEntry)
+	for notifyType, delta := range deltas {
+		entry.counts[notifyType] = max(entry.counts[notifyType]+delta, 0)
+	}
+	return nil
+}
+
+func (c *LRUCache) Delete(ctx context.Context, accountID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, exist := c.items[accountID]; exist {
+		c.remove(elem)
+	}
+	return nil
+}
+
+func (c *LRUCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*lruEntry).accountID)
+}
+
+// RedisClient RedisCache依赖的最小客户端接口，可用go-redis等客户端适配
+type RedisClient interface {
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) error
+	Del(ctx context.Context, keys ...string) error
+}
+
+// redisMarkerField 标记缓存已初始化，避免没有未读时被当作未命中
+const redisMarkerField = "_"
+
+// setScript 原子地重写hash并设置过期时间，ARGV[1]为过期毫秒数，其后为field/value对
+const setScript = `
+redis.call('DEL', KEYS[1])
+redis.call('HSET', KEYS[1], unpack(ARGV, 2))
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return 1`
+
+// addScript 仅在缓存存在时累加，ARGV为field/delta对
+const addScript = `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+for i = 1, #ARGV, 2 do
+	if redis.call('HINCRBY', KEYS[1], ARGV[i], ARGV[i + 1]) < 0 then
+		redis.call('HSET', KEYS[1], ARGV[i], 0)
+	end
+end
+return 1`
+
+// RedisCache 基于Redis hash的共享缓存，多个实例之间共享未读数
+type RedisCache struct {
+	client RedisClient
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisCache 初始化Redis缓存，key为prefix+账号ID
+func NewRedisCache(client RedisClient, prefix string, ttl time.Duration) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix, ttl: ttl}
+}
+
+func (c *RedisCache) key(accountID int64) string {
+	return c.prefix + strconv.FormatInt(accountID, 10)
+}
+
+func (c *RedisCache) Get(ctx context.Context, accountID int64) (map[int8]int64, bool, error) {
+	fields, err := c.client.HGetAll(ctx, c.key(accountID))
+	if err != nil {
+		return nil, false, err
+	}
+	if _, exist := fields[redisMarkerField]; !exist {
+		return nil, false, nil
+	}
+	counts := make(map[int8]int64, len(fields))
+	for field, value := range fields {
+		if field == redisMarkerField {
+			continue
+		}
+		notifyType, err := strconv.ParseInt(field, 10, 8)
+		if err != nil {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("parse unread count %s failed: %w", field, err)
+		}
+		counts[int8(notifyType)] = count
+	}
+	return counts, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, accountID int64, counts map[int8]int64) error {
+	args := []interface{}{c.ttl.Milliseconds(), redisMarkerField, 0}
+	for notifyType, count := range counts {
+		args = append(args, strconv.Itoa(int(notifyType)), count)
+	}
+	return c.client.Eval(ctx, setScript, []string{c.key(accountID)}, args...)
+}
+
+func (c *RedisCache) Add(ctx context.Context, accountID int64, deltas map[int8]int64) error {
+	args := make([]interface{}, 0, len(deltas)*2)
+	for notifyType, delta := range deltas {
+		args = append(args, strconv.Itoa(int(notifyType)), delta)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return c.client.Eval(ctx, addScript, []string{c.key(accountID)}, args...)
+}
+
+func (c *RedisCache) Delete(ctx context.Context, accountID int64) error {
+	return c.client.Del(ctx, c.key(accountID))
+}
+
+func copyCounts(counts map[int8]int64) map[int8]int64 {
+	copied := make(map[int8]int64, len(counts))
+	for notifyType, count := range counts {
+		copied[notifyType] = count
+	}
+	return copied
+}
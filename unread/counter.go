@@ -0,0 +1,135 @@
+package unread
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereal3x/apc/logger"
+	"github.com/ethereal3x/notice/repo"
+	"go.uber.org/zap"
+)
+
+// Counter 未读数服务，按缓存顺序逐级读取，全部未命中时查询数据库并回填
+// 作为repo.UnreadObserver注册后，通知的插入、已读、删除、归档会增量更新缓存
+// 通知到期不会触发回调，已过期的通知最多在缓存ttl内仍计入未读数
+type Counter struct {
+	repo   repo.NotificationStore
+	caches []Cache
+
+	mu      sync.Mutex
+	touched map[int64]struct{} // 上次校准之后有变化的账号
+}
+
+// NewCounter 初始化未读数服务，caches按由近到远排列，例如进程内LRU在前、Redis在后
+func NewCounter(repo repo.NotificationStore, caches ...Cache) *Counter {
+	return &Counter{
+		repo:    repo,
+		caches:  caches,
+		touched: make(map[int64]struct{}),
+	}
+}
+
+// UnreadCounts 获取账号各通知类型的未读数
+func (c *Counter) UnreadCounts(ctx context.Context, accountID int64) (map[int8]int64, error) {
+	for i, cache := range c.caches {
+		counts, hit, err := cache.Get(ctx, accountID)
+		if err != nil {
+			logger.ContextWarn(ctx, "unread.Counter: read cache failed",
+				zap.Int64("account_id", accountID),
+				zap.Error(err))
+			continue
+		}
+		if hit {
+			c.fill(ctx, c.caches[:i], accountID, counts)
+			return counts, nil
+		}
+	}
+
+	counts, err := c.repo.GetUnreadCountsByType(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	c.fill(ctx, c.caches, accountID, counts)
+	return counts, nil
+}
+
+// UnreadCount 获取账号的未读总数
+func (c *Counter) UnreadCount(ctx context.Context, accountID int64) (int64, error) {
+	counts, err := c.UnreadCounts(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	return total, nil
+}
+
+// UnreadChanged 实现repo.UnreadObserver，增量更新已缓存的未读数
+func (c *Counter) UnreadChanged(ctx context.Context, accountID int64, deltas map[int8]int64) {
+	for _, cache := range c.caches {
+		if err := cache.Add(ctx, accountID, deltas); err != nil {
+			// 增量失败时删除缓存，下次读取回源
+			logger.ContextWarn(ctx, "unread.Counter: update cache failed, invalidate",
+				zap.Int64("account_id", accountID),
+				zap.Error(err))
+			_ = cache.Delete(ctx, accountID)
+		}
+	}
+	c.mu.Lock()
+	c.touched[accountID] = struct{}{}
+	c.mu.Unlock()
+}
+
+// Reconcile 从数据库重新统计账号的未读数并覆盖缓存，修正并发或过期导致的偏差
+func (c *Counter) Reconcile(ctx context.Context, accountID int64) error {
+	counts, err := c.repo.GetUnreadCountsByType(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	c.fill(ctx, c.caches, accountID, counts)
+	return nil
+}
+
+// Start 后台按interval校准期间有变化的账号，ctx取消后停止
+func (c *Counter) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.reconcileTouched(ctx)
+			}
+		}
+	}()
+}
+
+func (c *Counter) reconcileTouched(ctx context.Context) {
+	c.mu.Lock()
+	touched := c.touched
+	c.touched = make(map[int64]struct{})
+	c.mu.Unlock()
+
+	for accountID := range touched {
+		if err := c.Reconcile(ctx, accountID); err != nil {
+			logger.ContextError(ctx, "unread.Counter: reconcile failed",
+				zap.Int64("account_id", accountID),
+				zap.Error(err))
+		}
+	}
+}
+
+func (c *Counter) fill(ctx context.Context, caches []Cache, accountID int64, counts map[int8]int64) {
+	for _, cache := range caches {
+		if err := cache.Set(ctx, accountID, counts); err != nil {
+			logger.ContextWarn(ctx, "unread.Counter: write cache failed",
+				zap.Int64("account_id", accountID),
+				zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package unread_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereal3x/notice/repo"
+	"github.com/ethereal3x/notice/unread"
+)
+
+// countingStore 统计按类型查询未读数的次数，用于判断是否命中缓存
+type countingStore struct {
+	repo.NotificationStore
+	queries int
+}
+
+func (s *countingStore) GetUnreadCountsByType(ctx context.Context, accountID int64) (map[int8]int64, error) {
+	s.queries++
+	return s.NotificationStore.GetUnreadCountsByType(ctx, accountID)
+}
+
+func newCachedStore(t *testing.T) (*unread.CachedStore, *countingStore) {
+	t.Helper()
+	memory := repo.NewMemoryStore()
+	counting := &countingStore{NotificationStore: memory}
+	counter := unread.NewCounter(counting, unread.NewLRUCache(10, time.Minute))
+	memory.WithUnreadObserver(counter)
+	return unread.NewCachedStore(counter), counting
+}
+
+func insert(t *testing.T, store repo.NotificationStore, accountID int64, notifyType int8, createdAt time.Time) *repo.Notification {
+	t.Helper()
+	n := &repo.Notification{AccountID: accountID, Type: notifyType, Title: "t", Content: "c", CreatedAt: createdAt}
+	if err := store.InsertNotice(context.Background(), n); err != nil {
+		t.Fatalf("InsertNotice: %v", err)
+	}
+	return n
+}
+
+func wantUnread(t *testing.T, store repo.NotificationStore, accountID int64, want int64) {
+	t.Helper()
+	count, err := store.GetUnreadCount(context.Background(), accountID)
+	if err != nil {
+		t.Fatalf("GetUnreadCount: %v", err)
+	}
+	if count != want {
+		t.Fatalf("GetUnreadCount = %d, want %d", count, want)
+	}
+}
+
+func TestCachedStoreReadsThroughCounter(t *testing.T) {
+	ctx := context.Background()
+	store, counting := newCachedStore(t)
+	now := time.Now()
+	first := insert(t, store, 1, 1, now)
+	insert(t, store, 1, 2, now)
+
+	wantUnread(t, store, 1, 2)
+	// 插入与已读增量更新缓存，不再查询存储
+	insert(t, store, 1, 2, now)
+	if _, err := store.MarkReadByIDs(ctx, 1, []uint64{first.ID}); err != nil {
+		t.Fatalf("MarkReadByIDs: %v", err)
+	}
+	wantUnread(t, store, 1, 2)
+	counts, err := store.GetUnreadCountsByType(ctx, 1)
+	if err != nil || counts[1] != 0 || counts[2] != 2 {
+		t.Fatalf("GetUnreadCountsByType = %v, %v", counts, err)
+	}
+	if counting.queries != 1 {
+		t.Fatalf("store queried %d times, want 1", counting.queries)
+	}
+}
+
+func TestArchiveUpdatesCachedUnread(t *testing.T) {
+	ctx := context.Background()
+	store, counting := newCachedStore(t)
+	old := time.Now().Add(-48 * time.Hour)
+	insert(t, store, 1, 1, old)
+	insert(t, store, 1, 1, old)
+	read := insert(t, store, 1, 1, old)
+	insert(t, store, 1, 1, time.Now())
+	insert(t, store, 2, 1, old)
+	if _, err := store.MarkReadByIDs(ctx, 1, []uint64{read.ID}); err != nil {
+		t.Fatalf("MarkReadByIDs: %v", err)
+	}
+	wantUnread(t, store, 1, 3)
+	wantUnread(t, store, 2, 1)
+
+	archived, err := store.ArchiveNotices(ctx, 1, time.Now().Add(-24*time.Hour), 100)
+	if err != nil || archived != 4 {
+		t.Fatalf("ArchiveNotices = %d, %v, want 4", archived, err)
+	}
+	wantUnread(t, store, 1, 1)
+	wantUnread(t, store, 2, 0)
+	if counting.queries != 2 {
+		t.Fatalf("store queried %d times, want 2", counting.queries)
+	}
+}
@@ -0,0 +1,29 @@
+package unread
+
+import (
+	"context"
+
+	"github.com/ethereal3x/notice/repo"
+)
+
+// CachedStore 包装通知存储，按类型的未读数经由Counter读取缓存，其余操作直接交给被包装的存储
+// GetUnreadSummary需要按分类统计，缓存中没有分类维度，仍查询存储
+type CachedStore struct {
+	repo.NotificationStore
+	counter *Counter
+}
+
+// NewCachedStore 以Counter的存储为底层初始化带未读数缓存的存储
+func NewCachedStore(counter *Counter) *CachedStore {
+	return &CachedStore{NotificationStore: counter.repo, counter: counter}
+}
+
+// GetUnreadCount 从缓存读取账号的未读总数
+func (s *CachedStore) GetUnreadCount(ctx context.Context, accountID int64) (int64, error) {
+	return s.counter.UnreadCount(ctx, accountID)
+}
+
+// GetUnreadCountsByType 从缓存读取账号各通知类型的未读数
+func (s *CachedStore) GetUnreadCountsByType(ctx context.Context, accountID int64) (map[int8]int64, error) {
+	return s.counter.UnreadCounts(ctx, accountID)
+}